@@ -51,6 +51,54 @@ func main() {
 				Usage:   "Timeout in seconds for each check.",
 				Value:   5,
 			},
+			&cli.IntFlag{
+				Name:    "retries",
+				EnvVars: []string{"RETRIES"},
+				Usage:   "Number of retries within a check before counting it as failed.",
+			},
+			&cli.IntFlag{
+				Name:    "retry-delay-ms",
+				EnvVars: []string{"RETRY_DELAY_MS"},
+				Usage:   "Delay between the attempts of a check, in milliseconds.",
+				Value:   500,
+			},
+			&cli.IntFlag{
+				Name:    "fail-threshold",
+				EnvVars: []string{"FAIL_THRESHOLD"},
+				Usage:   "Number of consecutive failed checks before declaring a host down.",
+				Value:   1,
+			},
+			&cli.IntFlag{
+				Name:    "recover-threshold",
+				EnvVars: []string{"RECOVER_THRESHOLD"},
+				Usage:   "Number of consecutive successful checks before declaring a host up again.",
+				Value:   1,
+			},
+			&cli.Float64SliceFlag{
+				Name:    "latency-buckets",
+				EnvVars: []string{"LATENCY_BUCKETS"},
+				Usage:   "Comma-separated list of buckets of the latency histogram, in seconds.",
+			},
+			&cli.BoolFlag{
+				Name:    "native-histograms",
+				EnvVars: []string{"NATIVE_HISTOGRAMS"},
+				Usage:   "Expose the latency histogram as a native histogram alongside the classic buckets.",
+			},
+			&cli.BoolFlag{
+				Name:    "watch-config",
+				EnvVars: []string{"WATCH_CONFIG"},
+				Usage:   "Reload the configuration whenever the configuration file changes.",
+			},
+			&cli.StringFlag{
+				Name:    "api-token",
+				EnvVars: []string{"API_TOKEN"},
+				Usage:   "Bearer token of the monitors and silences APIs and of the reload endpoint, which are disabled without it.",
+			},
+			&cli.StringFlag{
+				Name:    "monitors-file",
+				EnvVars: []string{"MONITORS_FILE"},
+				Usage:   "File the monitors created through the API are persisted to.",
+			},
 			&cli.IntFlag{
 				Name:    "port",
 				Aliases: []string{"p"},
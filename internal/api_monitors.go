@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// checkResponse is the result of an immediate check, as returned by the monitors API.
+type checkResponse struct {
+	Up         bool   `json:"up"`
+	LatencyMs  int64  `json:"latency_ms"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// monitorsRoutes returns the routes of the monitors API, which requires the bearer token. It is disabled without token.
+func (s *Supervisor) monitorsRoutes() []Route {
+	if s.monitorsAPI.token == "" {
+		return nil
+	}
+
+	return withToken(s.monitorsAPI.token, []Route{
+		{Pattern: "GET /api/v1/monitors", Handler: http.HandlerFunc(s.listMonitors)},
+		{Pattern: "POST /api/v1/monitors", Handler: http.HandlerFunc(s.createMonitor)},
+		{Pattern: "GET /api/v1/monitors/{id}", Handler: http.HandlerFunc(s.getMonitor)},
+		{Pattern: "PUT /api/v1/monitors/{id}", Handler: http.HandlerFunc(s.updateMonitor)},
+		{Pattern: "DELETE /api/v1/monitors/{id}", Handler: http.HandlerFunc(s.deleteMonitor)},
+		{Pattern: "POST /api/v1/monitors/{id}/pause", Handler: http.HandlerFunc(s.pauseMonitor)},
+		{Pattern: "POST /api/v1/monitors/{id}/resume", Handler: http.HandlerFunc(s.resumeMonitor)},
+		{Pattern: "POST /api/v1/monitors/{id}/check", Handler: http.HandlerFunc(s.checkMonitor)},
+	})
+}
+
+// listMonitors lists every monitor.
+func (s *Supervisor) listMonitors(w http.ResponseWriter, _ *http.Request) {
+	monitors := s.Monitors()
+	if monitors == nil {
+		monitors = []Monitor{}
+	}
+
+	writeJSON(w, http.StatusOK, monitors)
+}
+
+// getMonitor returns the monitor identified in the path.
+func (s *Supervisor) getMonitor(w http.ResponseWriter, r *http.Request) {
+	monitor, err := s.Monitor(r.PathValue("id"))
+	if err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, monitor)
+}
+
+// createMonitor creates a monitor from the body of the request.
+func (s *Supervisor) createMonitor(w http.ResponseWriter, r *http.Request) {
+	host := s.newMonitorHost()
+	if err := json.NewDecoder(r.Body).Decode(&host); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+
+	monitor, err := s.AddMonitor(host)
+	if err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, monitor)
+}
+
+// updateMonitor replaces the monitor identified in the path with the body of the request.
+func (s *Supervisor) updateMonitor(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("id")
+
+	host := s.newMonitorHost()
+	if err := json.NewDecoder(r.Body).Decode(&host); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	if host.Name != "" && host.Name != name {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("name [%s] doesn't match the monitor [%s]", host.Name, name))
+		return
+	}
+
+	monitor, err := s.UpdateMonitor(name, host)
+	if err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, monitor)
+}
+
+// deleteMonitor removes the monitor identified in the path.
+func (s *Supervisor) deleteMonitor(w http.ResponseWriter, r *http.Request) {
+	if err := s.RemoveMonitor(r.PathValue("id")); err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pauseMonitor stops checking the monitor identified in the path.
+func (s *Supervisor) pauseMonitor(w http.ResponseWriter, r *http.Request) {
+	s.setMonitorPaused(w, r, true)
+}
+
+// resumeMonitor starts checking the monitor identified in the path again.
+func (s *Supervisor) resumeMonitor(w http.ResponseWriter, r *http.Request) {
+	s.setMonitorPaused(w, r, false)
+}
+
+// setMonitorPaused pauses or resumes the monitor identified in the path.
+func (s *Supervisor) setMonitorPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	monitor, err := s.PauseMonitor(r.PathValue("id"), paused)
+	if err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, monitor)
+}
+
+// checkMonitor checks the monitor identified in the path immediately, and returns the result.
+func (s *Supervisor) checkMonitor(w http.ResponseWriter, r *http.Request) {
+	result, err := s.CheckMonitor(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	response := checkResponse{
+		Up:         result.Up,
+		LatencyMs:  result.Latency.Milliseconds(),
+		StatusCode: result.StatusCode,
+		Reason:     result.Reason,
+	}
+	if result.Err != nil {
+		response.Error = result.Err.Error()
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// writeMonitorError writes the error of a monitor operation, with the matching status code.
+func writeMonitorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errMonitorNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, errMonitorExists), errors.Is(err, errMonitorReadOnly), errors.Is(err, errMonitorPaused):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusServiceUnavailable, err)
+	default:
+		writeError(w, http.StatusBadRequest, err)
+	}
+}
+
+// withToken returns the routes, rejecting the requests which don't carry the bearer token.
+func withToken(token string, routes []Route) []Route {
+	output := make([]Route, 0, len(routes))
+	for _, route := range routes {
+		output = append(output, Route{Pattern: route.Pattern, Handler: requireToken(token, route.Handler)})
+	}
+
+	return output
+}
+
+// requireToken rejects the requests which don't carry the bearer token.
+func requireToken(token string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("invalid or missing bearer token"))
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAPIToken = "secret"
+
+// setupMonitorsAPI creates a supervisor with the monitors API enabled, running the hosts of the configuration.
+func setupMonitorsAPI(t *testing.T, path string, hosts ...Host) (*Supervisor, *httptest.Server) {
+	supervisor, err := NewSupervisor(prometheus.NewRegistry(), NewProber(nil, Host{}), nil, WithMonitorsAPI(testAPIToken, Host{
+		Timeout:  1,
+		Interval: 60,
+		Headers:  map[string]string{"User-Agent": "Uptimer/test"},
+	}, path))
+	assert.NoError(t, err)
+	assert.NoError(t, supervisor.loadMonitors())
+	assert.NoError(t, supervisor.Apply(Config{Hosts: hosts}))
+	t.Cleanup(func() {
+		assert.NoError(t, supervisor.Shutdown(context.Background()))
+	})
+
+	return supervisor, setupAPIServer(t, supervisor.Routes())
+}
+
+// callMonitorsAPI sends an authenticated request to the monitors API, decoding the response into output.
+func callMonitorsAPI(t *testing.T, method, url, body string, output any) int {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+testAPIToken)
+
+	res, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	if output != nil {
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(output))
+	} else {
+		_, _ = io.Copy(io.Discard, res.Body)
+	}
+
+	return res.StatusCode
+}
+
+func TestMonitorsAPIWithoutTokenExpectUnauthorized(t *testing.T) {
+	_, server := setupMonitorsAPI(t, "")
+
+	res, err := http.Get(server.URL + "/api/v1/monitors")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/monitors", nil)
+	assert.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer wrong")
+	res, err = http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestMonitorsAPIDisabledWithoutToken(t *testing.T) {
+	supervisor, err := NewSupervisor(prometheus.NewRegistry(), NewProber(nil, Host{}), nil)
+	assert.NoError(t, err)
+
+	assert.Empty(t, supervisor.monitorsRoutes())
+}
+
+func TestMonitorsAPIWithCreateUpdateDeleteExpectLifecycle(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	supervisor, server := setupMonitorsAPI(t, "", Host{Name: "website", Host: "http://example.com", Interval: 60, Timeout: 1})
+
+	var created Monitor
+	status := callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors",
+		`{"name": "orders", "host": "`+target.URL+`", "tags": ["shop"], "headers": {"X-Api-Key": "123"}}`, &created)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, sourceAPI, created.Source)
+	assert.Equal(t, 60, created.Interval)
+	assert.Equal(t, map[string]string{"User-Agent": "Uptimer/test", "X-Api-Key": "123"}, created.Headers)
+	assert.Contains(t, supervisor.seekers, target.URL)
+
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors", `{"name": "orders", "host": "http://other.example.com"}`, nil)
+	assert.Equal(t, http.StatusConflict, status)
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors", `{"name": "invalid", "host": "example.com"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors", `{"name": "secrets", "host": "http://other.example.com", "http": {"body_file": "/etc/passwd"}}`, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors", `{"name": "secrets", "host": "https://other.example.com", "tls": {"ca_file": "/etc/passwd"}}`, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	var monitors []Monitor
+	status = callMonitorsAPI(t, http.MethodGet, server.URL+"/api/v1/monitors", "", &monitors)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, monitors, 2)
+	assert.Equal(t, "orders", monitors[0].Name)
+	assert.Equal(t, sourceConfig, monitors[1].Source)
+
+	var updated Monitor
+	status = callMonitorsAPI(t, http.MethodPut, server.URL+"/api/v1/monitors/orders", `{"host": "`+target.URL+`", "interval": 30}`, &updated)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 30, updated.Interval)
+	assert.Equal(t, 30, supervisor.seekers[target.URL].seeker.interval)
+
+	var result checkResponse
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors/orders/check", "", &result)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, result.Up)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+
+	var paused Monitor
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors/orders/pause", "", &paused)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, paused.Paused)
+	assert.NotContains(t, supervisor.seekers, target.URL)
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors/orders/check", "", nil)
+	assert.Equal(t, http.StatusConflict, status)
+
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors/orders/resume", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, supervisor.seekers, target.URL)
+
+	// the hosts of the configuration can only be paused and resumed
+	status = callMonitorsAPI(t, http.MethodDelete, server.URL+"/api/v1/monitors/website", "", nil)
+	assert.Equal(t, http.StatusConflict, status)
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors/website/pause", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	status = callMonitorsAPI(t, http.MethodDelete, server.URL+"/api/v1/monitors/orders", "", nil)
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.NotContains(t, supervisor.seekers, target.URL)
+	status = callMonitorsAPI(t, http.MethodGet, server.URL+"/api/v1/monitors/orders", "", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestMonitorsAPIWithFileExpectMonitorsPersisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitors.json")
+	_, server := setupMonitorsAPI(t, path)
+
+	status := callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors", `{"name": "orders", "host": "http://orders.example.com"}`, nil)
+	assert.Equal(t, http.StatusCreated, status)
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/api/v1/monitors/orders/pause", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	restarted, _ := setupMonitorsAPI(t, path)
+	monitor, err := restarted.Monitor("orders")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://orders.example.com", monitor.Host.Host)
+	assert.Equal(t, sourceAPI, monitor.Source)
+	assert.True(t, monitor.Paused)
+}
+
+func TestSupervisorReloadWithMonitorsExpectMonitorsKept(t *testing.T) {
+	supervisor, _ := setupMonitorsAPI(t, "")
+	_, err := supervisor.AddMonitor(Host{Name: "orders", Host: "http://orders.example.com", Interval: 60, Timeout: 1})
+	assert.NoError(t, err)
+
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{{Name: "website", Host: "http://example.com", Interval: 60, Timeout: 1}}}))
+	assert.Contains(t, supervisor.seekers, "http://orders.example.com")
+
+	// a host of the configuration can't take the name of a monitor
+	assert.Error(t, supervisor.Apply(Config{Hosts: []Host{{Name: "orders", Host: "http://example.com", Interval: 60, Timeout: 1}}}))
+}
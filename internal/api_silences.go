@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// silenceRequest is the body of the requests creating a silence. The end is either given, or computed
+// from the start and the duration.
+type silenceRequest struct {
+	Hosts     []string  `json:"hosts"`
+	Tags      []string  `json:"tags"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Duration  string    `json:"duration"`
+	Comment   string    `json:"comment"`
+	CreatedBy string    `json:"created_by"`
+}
+
+// Routes returns the routes of the silences API, without authentication. The supervisor serves them behind
+// the bearer token of the monitors API.
+func (m *Maintenance) Routes() []Route {
+	return []Route{
+		{Pattern: "GET /api/v1/silences", Handler: http.HandlerFunc(m.listSilences)},
+		{Pattern: "POST /api/v1/silences", Handler: http.HandlerFunc(m.createSilence)},
+		{Pattern: "DELETE /api/v1/silences/{id}", Handler: http.HandlerFunc(m.deleteSilence)},
+	}
+}
+
+// silencesRoutes returns the routes of the silences API, which requires the bearer token of the monitors
+// API. It is disabled without token.
+func (s *Supervisor) silencesRoutes() []Route {
+	if s.monitorsAPI.token == "" {
+		return nil
+	}
+
+	return withToken(s.monitorsAPI.token, s.maintenance.Routes())
+}
+
+// listSilences lists the silences which are not expired yet.
+func (m *Maintenance) listSilences(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, m.Silences())
+}
+
+// createSilence creates a silence from the body of the request.
+func (m *Maintenance) createSilence(w http.ResponseWriter, r *http.Request) {
+	var request silenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+
+	silence := Silence{
+		Hosts:     request.Hosts,
+		Tags:      request.Tags,
+		StartsAt:  request.StartsAt,
+		EndsAt:    request.EndsAt,
+		Comment:   request.Comment,
+		CreatedBy: request.CreatedBy,
+	}
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = time.Now()
+	}
+
+	if request.Duration != "" {
+		duration, err := time.ParseDuration(request.Duration)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid duration: %w", err))
+			return
+		}
+		silence.EndsAt = silence.StartsAt.Add(duration)
+	}
+
+	silence, err := m.AddSilence(silence)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, silence)
+}
+
+// deleteSilence removes the silence identified in the path.
+func (m *Maintenance) deleteSilence(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !m.RemoveSilence(id) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("silence [%s] not found", id))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupAPIServer starts a server with the given routes.
+func setupAPIServer(t *testing.T, routes []Route) *httptest.Server {
+	mux := http.NewServeMux()
+	for _, route := range routes {
+		mux.Handle(route.Pattern, route.Handler)
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestSilencesAPIWithCreateListDeleteExpectLifecycle(t *testing.T) {
+	maintenance, err := NewMaintenance(nil)
+	assert.NoError(t, err)
+	server := setupAPIServer(t, maintenance.Routes())
+
+	res, err := http.Post(server.URL+"/api/v1/silences", "application/json", strings.NewReader(
+		`{"hosts": ["api"], "duration": "30m", "comment": "deploy", "created_by": "alice"}`,
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var created Silence
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&created))
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, []string{"api"}, created.Hosts)
+	assert.Equal(t, "deploy", created.Comment)
+
+	res, err = http.Get(server.URL + "/api/v1/silences")
+	assert.NoError(t, err)
+	var silences []Silence
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&silences))
+	if assert.Len(t, silences, 1) {
+		assert.Equal(t, created.ID, silences[0].ID)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/api/v1/silences/"+created.ID, nil)
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestSilencesAPIWithInvalidSilenceExpectBadRequest(t *testing.T) {
+	maintenance, err := NewMaintenance(nil)
+	assert.NoError(t, err)
+	server := setupAPIServer(t, maintenance.Routes())
+
+	for _, body := range []string{
+		`not json`,
+		`{"hosts": ["api"], "duration": "soon"}`,
+		`{"duration": "30m"}`,
+		`{"hosts": ["api"]}`,
+	} {
+		res, err := http.Post(server.URL+"/api/v1/silences", "application/json", strings.NewReader(body))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+	}
+}
+
+func TestSilencesAPIWithoutTokenExpectUnauthorized(t *testing.T) {
+	supervisor, server := setupMonitorsAPI(t, "")
+
+	res, err := http.Post(server.URL+"/api/v1/silences", "application/json", strings.NewReader(
+		`{"tags": ["frontend"], "duration": "30m"}`,
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Empty(t, supervisor.maintenance.Silences())
+
+	var silences []Silence
+	assert.Equal(t, http.StatusOK, callMonitorsAPI(t, http.MethodGet, server.URL+"/api/v1/silences", "", &silences))
+}
+
+func TestSilencesAPIDisabledWithoutToken(t *testing.T) {
+	supervisor, _ := setupSupervisor(t, nil)
+
+	assert.Empty(t, supervisor.silencesRoutes())
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"net/http"
+	"time"
+)
+
+// States of the hosts in the status API, besides up and down.
+const (
+	statusUnknown = "unknown"
+	statusPaused  = "paused"
+)
+
+// hostStatus is the current state of a host, as returned by the status API.
+type hostStatus struct {
+	Name string   `json:"name"`
+	Host string   `json:"host"`
+	Tags []string `json:"tags,omitempty"`
+	// State is up or down, unknown until the host is checked, or paused.
+	State               string     `json:"state"`
+	LastCheck           *time.Time `json:"last_check,omitempty"`
+	LatencyMs           int64      `json:"latency_ms"`
+	StatusCode          int        `json:"status_code,omitempty"`
+	Reason              string     `json:"reason,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastChange          *time.Time `json:"last_change,omitempty"`
+	// SinceLastChange is the number of seconds since the host last changed state.
+	SinceLastChange int64 `json:"since_last_change_seconds"`
+	Flapping        bool  `json:"flapping"`
+	Maintenance     bool  `json:"maintenance"`
+	Escalated       bool  `json:"escalated"`
+}
+
+// statusRoutes returns the routes of the status API, which is read-only and not authenticated, as the metrics.
+func (s *Supervisor) statusRoutes() []Route {
+	return []Route{
+		{Pattern: "GET /api/v1/status", Handler: http.HandlerFunc(s.listStatus)},
+		{Pattern: "GET /api/v1/status/{id}", Handler: http.HandlerFunc(s.getStatus)},
+	}
+}
+
+// listStatus returns the status of every host.
+func (s *Supervisor) listStatus(w http.ResponseWriter, _ *http.Request) {
+	output := []hostStatus{}
+	for _, monitor := range s.Monitors() {
+		output = append(output, s.status(monitor))
+	}
+
+	writeJSON(w, http.StatusOK, output)
+}
+
+// getStatus returns the status of the host identified in the path.
+func (s *Supervisor) getStatus(w http.ResponseWriter, r *http.Request) {
+	monitor, err := s.Monitor(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.status(monitor))
+}
+
+// status returns the current state of a monitor, as published by its seeker.
+func (s *Supervisor) status(monitor Monitor) hostStatus {
+	output := hostStatus{
+		Name:  monitor.Name,
+		Host:  monitor.Host.Host,
+		Tags:  monitor.Tags,
+		State: statusUnknown,
+	}
+	if monitor.Paused {
+		output.State = statusPaused
+		return output
+	}
+
+	state, ok := s.states.Get(monitor.Name)
+	if !ok {
+		return output
+	}
+
+	output.State = stateDown
+	if state.Up {
+		output.State = stateUp
+	}
+	output.LastCheck = &state.LastCheck
+	output.LatencyMs = state.Latency.Milliseconds()
+	output.StatusCode = state.StatusCode
+	output.Reason = state.Reason
+	output.ConsecutiveFailures = state.ConsecutiveFailures
+	output.LastChange = &state.LastChange
+	output.SinceLastChange = int64(time.Since(state.LastChange).Seconds())
+	output.Flapping = state.Flapping
+	output.Maintenance = state.Maintenance
+	output.Escalated = state.Escalated
+
+	return output
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getStatus returns the status of every host from the status API, which requires no token.
+func getStatus(t *testing.T, url string) []hostStatus {
+	res, err := http.Get(url + "/api/v1/status")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	var output []hostStatus
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&output))
+
+	return output
+}
+
+func TestStatusAPIWithHostsExpectCurrentState(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer target.Close()
+
+	supervisor, server := setupMonitorsAPI(t, "",
+		Host{Name: "api", Host: target.URL, Tags: []string{"prod"}, Timeout: 1, Interval: 60},
+		Host{Name: "idle", Host: target.URL + "/idle", Timeout: 1, Interval: 60},
+		Host{Name: "paused", Host: target.URL + "/paused", Timeout: 1, Interval: 60},
+	)
+	_, err := supervisor.PauseMonitor("paused", true)
+	assert.NoError(t, err)
+
+	_, err = supervisor.CheckMonitor(context.Background(), "api")
+	assert.NoError(t, err)
+
+	status := getStatus(t, server.URL)
+	assert.Len(t, status, 3)
+
+	api := status[0]
+	assert.Equal(t, "api", api.Name)
+	assert.Equal(t, target.URL, api.Host)
+	assert.Equal(t, []string{"prod"}, api.Tags)
+	assert.Equal(t, stateDown, api.State)
+	assert.NotNil(t, api.LastCheck)
+	assert.Equal(t, http.StatusServiceUnavailable, api.StatusCode)
+	assert.NotEmpty(t, api.Reason)
+	assert.Equal(t, 1, api.ConsecutiveFailures)
+	assert.NotNil(t, api.LastChange)
+	assert.Less(t, api.SinceLastChange, int64(5))
+
+	assert.Equal(t, "idle", status[1].Name)
+	assert.Equal(t, statusUnknown, status[1].State)
+	assert.Nil(t, status[1].LastCheck)
+
+	assert.Equal(t, "paused", status[2].Name)
+	assert.Equal(t, statusPaused, status[2].State)
+}
+
+func TestStatusAPIWithHostExpectSingleStatus(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	supervisor, server := setupMonitorsAPI(t, "", Host{Name: "api", Host: target.URL, Timeout: 1, Interval: 60})
+	_, err := supervisor.CheckMonitor(context.Background(), "api")
+	assert.NoError(t, err)
+
+	res, err := http.Get(server.URL + "/api/v1/status/api")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	var status hostStatus
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&status))
+	assert.Equal(t, stateUp, status.State)
+	assert.Equal(t, http.StatusOK, status.StatusCode)
+	assert.Empty(t, status.Reason)
+	assert.Zero(t, status.ConsecutiveFailures)
+
+	res, err = http.Get(server.URL + "/api/v1/status/missing")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
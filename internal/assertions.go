@@ -0,0 +1,283 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AssertionOptions holds the assertions a response body must satisfy for the host to be considered as up.
+type AssertionOptions struct {
+	// Contains lists substrings the body must contain.
+	Contains []string `json:"contains,omitempty"`
+	// NotContains lists substrings the body must not contain.
+	NotContains []string `json:"not_contains,omitempty"`
+	// Regex lists regular expressions the body must match.
+	Regex []string `json:"regex,omitempty"`
+	// JSON lists JSONPath expressions, optionally compared to a JSON literal (e.g. `$.status == "ok"`).
+	JSON []string `json:"json,omitempty"`
+}
+
+// bodyAssertion is a single compiled assertion on a response body.
+type bodyAssertion struct {
+	name  string
+	check func(body []byte) error
+}
+
+// AssertionError is returned when a response body doesn't satisfy an assertion.
+type AssertionError struct {
+	Assertion string
+	Err       error
+}
+
+func (e *AssertionError) Error() string {
+	return fmt.Sprintf("assertion [%s] failed: %v", e.Assertion, e.Err)
+}
+
+func (e *AssertionError) Unwrap() error {
+	return e.Err
+}
+
+// compileAssertions compiles the assertions of the options, in the order they are checked.
+func compileAssertions(options AssertionOptions) ([]bodyAssertion, error) {
+	var output []bodyAssertion
+
+	for _, substring := range options.Contains {
+		output = append(output, bodyAssertion{
+			name: fmt.Sprintf("contains %q", substring),
+			check: func(body []byte) error {
+				if !bytes.Contains(body, []byte(substring)) {
+					return errors.New("substring not found in body")
+				}
+				return nil
+			},
+		})
+	}
+
+	for _, substring := range options.NotContains {
+		output = append(output, bodyAssertion{
+			name: fmt.Sprintf("not contains %q", substring),
+			check: func(body []byte) error {
+				if bytes.Contains(body, []byte(substring)) {
+					return errors.New("substring found in body")
+				}
+				return nil
+			},
+		})
+	}
+
+	for _, expression := range options.Regex {
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex assertion [%s]: %w", expression, err)
+		}
+
+		output = append(output, bodyAssertion{
+			name: fmt.Sprintf("regex %q", expression),
+			check: func(body []byte) error {
+				if !re.Match(body) {
+					return errors.New("body does not match")
+				}
+				return nil
+			},
+		})
+	}
+
+	for _, expression := range options.JSON {
+		assertion, err := parseJSONAssertion(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid json assertion [%s]: %w", expression, err)
+		}
+
+		output = append(output, bodyAssertion{
+			name:  fmt.Sprintf("json %s", expression),
+			check: assertion.check,
+		})
+	}
+
+	return output, nil
+}
+
+// runAssertions checks the body against each assertion and returns an AssertionError for the first failing one.
+func runAssertions(assertions []bodyAssertion, body []byte) error {
+	for _, assertion := range assertions {
+		if err := assertion.check(body); err != nil {
+			return &AssertionError{Assertion: assertion.name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// jsonAssertion is a JSONPath expression optionally compared to a literal.
+// Only the child (`.name`, `['name']`) and index (`[0]`) operators are supported.
+type jsonAssertion struct {
+	path     []any
+	operator string
+	value    any
+}
+
+// parseJSONAssertion parses an expression such as `$.items[0].status == "ok"`.
+// Without comparison, the assertion only requires the path to exist.
+func parseJSONAssertion(expression string) (*jsonAssertion, error) {
+	expression = strings.TrimSpace(expression)
+	if !strings.HasPrefix(expression, "$") {
+		return nil, errors.New("expression must start with $")
+	}
+
+	assertion := &jsonAssertion{}
+	i := 1
+	for i < len(expression) {
+		switch expression[i] {
+		case '.':
+			end := i + 1
+			for end < len(expression) && !strings.ContainsRune(".[ =!<>", rune(expression[end])) {
+				end++
+			}
+			if end == i+1 {
+				return nil, fmt.Errorf("empty key at position %d", i)
+			}
+			assertion.path = append(assertion.path, expression[i+1:end])
+			i = end
+		case '[':
+			end := strings.IndexByte(expression[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket at position %d", i)
+			}
+			inner := expression[i+1 : i+end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				assertion.path = append(assertion.path, inner[1:len(inner)-1])
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index [%s]", inner)
+				}
+				assertion.path = append(assertion.path, index)
+			}
+			i += end + 1
+		default:
+			return assertion, assertion.parseComparison(expression[i:])
+		}
+	}
+
+	return assertion, nil
+}
+
+// parseComparison parses the comparison following the path, such as `== "ok"` or `< 1000`.
+func (a *jsonAssertion) parseComparison(comparison string) error {
+	comparison = strings.TrimSpace(comparison)
+	if comparison == "" {
+		return nil
+	}
+
+	for _, operator := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(comparison, operator) {
+			a.operator = operator
+			break
+		}
+	}
+	if a.operator == "" {
+		return fmt.Errorf("unsupported comparison [%s]", comparison)
+	}
+
+	literal := strings.TrimSpace(strings.TrimPrefix(comparison, a.operator))
+	if err := json.Unmarshal([]byte(literal), &a.value); err != nil {
+		return fmt.Errorf("invalid literal [%s]: %w", literal, err)
+	}
+
+	if _, ok := a.value.(float64); !ok && a.operator != "==" && a.operator != "!=" {
+		return fmt.Errorf("operator [%s] requires a number", a.operator)
+	}
+
+	return nil
+}
+
+// check decodes the body as JSON and evaluates the assertion.
+func (a *jsonAssertion) check(body []byte) error {
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return fmt.Errorf("body is not valid JSON: %w", err)
+	}
+
+	value, err := a.lookup(document)
+	if err != nil {
+		return err
+	}
+
+	switch a.operator {
+	case "":
+		return nil
+	case "==", "!=":
+		if reflect.DeepEqual(value, a.value) != (a.operator == "==") {
+			return fmt.Errorf("got %s", formatJSON(value))
+		}
+		return nil
+	}
+
+	number, ok := value.(float64)
+	if !ok {
+		return fmt.Errorf("got %s, which is not a number", formatJSON(value))
+	}
+
+	expected := a.value.(float64)
+	var matches bool
+	switch a.operator {
+	case "<":
+		matches = number < expected
+	case "<=":
+		matches = number <= expected
+	case ">":
+		matches = number > expected
+	case ">=":
+		matches = number >= expected
+	}
+	if !matches {
+		return fmt.Errorf("got %s", formatJSON(value))
+	}
+
+	return nil
+}
+
+// lookup follows the path in the decoded document.
+func (a *jsonAssertion) lookup(document any) (any, error) {
+	current := document
+	for _, segment := range a.path {
+		switch key := segment.(type) {
+		case string:
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot read key [%s] of %s", key, formatJSON(current))
+			}
+			current, ok = object[key]
+			if !ok {
+				return nil, fmt.Errorf("key [%s] not found", key)
+			}
+		case int:
+			array, ok := current.([]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot read index [%d] of %s", key, formatJSON(current))
+			}
+			if key < 0 || key >= len(array) {
+				return nil, fmt.Errorf("index [%d] out of range", key)
+			}
+			current = array[key]
+		}
+	}
+
+	return current, nil
+}
+
+// formatJSON formats a decoded JSON value for error messages.
+func formatJSON(value any) string {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(content)
+}
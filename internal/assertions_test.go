@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const assertionBody = `{"status": "ok", "queue_depth": 42, "items": [{"name": "first"}], "meta data": {"healthy": true}}`
+
+func TestRunAssertionsWithSatisfiedAssertionsExpectNoError(t *testing.T) {
+	assertions, err := compileAssertions(AssertionOptions{
+		Contains:    []string{`"status"`},
+		NotContains: []string{"maintenance"},
+		Regex:       []string{`"queue_depth":\s*\d+`},
+		JSON: []string{
+			`$.status == "ok"`,
+			`$.queue_depth < 1000`,
+			`$.queue_depth >= 42`,
+			`$.items[0].name != "second"`,
+			`$['meta data'].healthy == true`,
+			`$.items`,
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, runAssertions(assertions, []byte(assertionBody)))
+}
+
+func TestRunAssertionsWithFailingAssertionExpectAssertionError(t *testing.T) {
+	tests := map[string]AssertionOptions{
+		`contains "degraded"`:         {Contains: []string{"degraded"}},
+		`not contains "ok"`:           {NotContains: []string{"ok"}},
+		`regex "^<html>"`:             {Regex: []string{"^<html>"}},
+		`json $.status == "degraded"`: {JSON: []string{`$.status == "degraded"`}},
+		`json $.queue_depth < 10`:     {JSON: []string{`$.queue_depth < 10`}},
+		`json $.status > 1`:           {JSON: []string{`$.status > 1`}},
+		`json $.items[3].name`:        {JSON: []string{`$.items[3].name`}},
+		`json $.missing`:              {JSON: []string{`$.missing`}},
+	}
+
+	for name, options := range tests {
+		assertions, err := compileAssertions(options)
+		assert.NoError(t, err)
+
+		err = runAssertions(assertions, []byte(assertionBody))
+		var assertionErr *AssertionError
+		if assert.ErrorAs(t, err, &assertionErr, name) {
+			assert.Equal(t, name, assertionErr.Assertion)
+		}
+	}
+}
+
+func TestRunJSONAssertionWithInvalidBodyExpectError(t *testing.T) {
+	assertions, err := compileAssertions(AssertionOptions{JSON: []string{`$.status == "ok"`}})
+	assert.NoError(t, err)
+
+	assert.Error(t, runAssertions(assertions, []byte("<html>Maintenance</html>")))
+}
+
+func TestCompileAssertionsWithInvalidExpressionsExpectError(t *testing.T) {
+	invalid := []AssertionOptions{
+		{Regex: []string{"("}},
+		{JSON: []string{"status == 1"}},
+		{JSON: []string{"$.status ~= 1"}},
+		{JSON: []string{"$.status == ok"}},
+		{JSON: []string{`$.status < "ok"`}},
+		{JSON: []string{"$.items[first]"}},
+	}
+
+	for _, options := range invalid {
+		_, err := compileAssertions(options)
+		assert.Error(t, err, "%+v", options)
+	}
+}
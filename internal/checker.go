@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UptimeChecker is the interface that defines how a single check is performed against a remote host.
+// Each target type (http, tcp, ...) provides its own implementation.
+type UptimeChecker interface {
+	Check(ctx context.Context) CheckResult
+}
+
+// CheckResult is the outcome of a single check performed by an UptimeChecker.
+type CheckResult struct {
+	Up         bool
+	Latency    time.Duration
+	StatusCode int
+	// Reason explains why the check failed. It defaults to the classification of Err when empty.
+	Reason string
+	Err    error
+}
+
+// checkType returns the type of check to perform on the host. When no type is configured,
+// it is inferred from the scheme of the host URL.
+func (h Host) checkType() string {
+	if h.Type != "" {
+		return h.Type
+	}
+
+	u, err := url.Parse(h.Host)
+	if err != nil {
+		return ""
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return "http"
+	default:
+		return u.Scheme
+	}
+}
+
+// newChecker creates the UptimeChecker matching the type of the host. Checkers exposing
+// their own metrics register them on the given registerer.
+func newChecker(host Host, registerer prometheus.Registerer) (UptimeChecker, error) {
+	switch host.checkType() {
+	case "http":
+		return NewHTTPChecker(host, registerer)
+	case "tcp":
+		return NewTCPChecker(host)
+	case "dns":
+		return NewDNSChecker(host, registerer)
+	case "tls":
+		return NewTLSChecker(host, registerer)
+	case "icmp":
+		return NewICMPChecker(host, registerer)
+	case "grpc":
+		return NewGRPCChecker(host, registerer)
+	default:
+		return nil, fmt.Errorf("unsupported check type [%s] for [%s]", host.checkType(), host.Host)
+	}
+}
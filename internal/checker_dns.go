@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+// DNSOptions holds the options specific to DNS checks.
+type DNSOptions struct {
+	// Resolver is the address of the resolver to query. Defaults to the first nameserver of /etc/resolv.conf.
+	Resolver string `json:"resolver,omitempty"`
+	// Record is the type of record to query (A, AAAA, CNAME, MX, TXT or SRV). Defaults to A.
+	Record string `json:"record,omitempty"`
+	// Expected is an optional list of answers. The answer set must match it exactly, in any order.
+	Expected []string `json:"expected,omitempty"`
+	// ExpectedRegex is an optional regular expression every answer must match.
+	ExpectedRegex string `json:"expected_regex,omitempty"`
+}
+
+// DNSChecker is the implementation of the UptimeChecker interface for DNS hosts (dns://name).
+// It queries a resolver for a record and asserts the answers.
+type DNSChecker struct {
+	logger        *logrus.Entry
+	client        *dns.Client
+	resolver      string
+	name          string
+	record        uint16
+	expected      []string
+	expectedRegex *regexp.Regexp
+	queryLatency  prometheus.Gauge
+	answers       prometheus.Gauge
+}
+
+// NewDNSChecker creates a new DNSChecker instance.
+func NewDNSChecker(host Host, registerer prometheus.Registerer) (*DNSChecker, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"component": "dns-checker",
+	})
+
+	u, err := url.Parse(host.Host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("dns host [%s] must be in the form dns://name", host.Host)
+	}
+
+	recordName := strings.ToUpper(host.DNS.Record)
+	if recordName == "" {
+		recordName = "A"
+	}
+	record, ok := dns.StringToType[recordName]
+	if !ok || !slices.Contains([]uint16{dns.TypeA, dns.TypeAAAA, dns.TypeCNAME, dns.TypeMX, dns.TypeTXT, dns.TypeSRV}, record) {
+		return nil, fmt.Errorf("unsupported record type [%s] for [%s]", host.DNS.Record, host.Host)
+	}
+
+	resolver := host.DNS.Resolver
+	if resolver == "" {
+		config, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+		if err != nil || len(config.Servers) == 0 {
+			return nil, fmt.Errorf("no resolver configured for [%s] and none found in /etc/resolv.conf", host.Host)
+		}
+		resolver = net.JoinHostPort(config.Servers[0], config.Port)
+	} else if _, _, err := net.SplitHostPort(resolver); err != nil {
+		resolver = net.JoinHostPort(resolver, "53")
+	}
+
+	var expectedRegex *regexp.Regexp
+	if host.DNS.ExpectedRegex != "" {
+		expectedRegex, err = regexp.Compile(host.DNS.ExpectedRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expected regex for [%s]: %w", host.Host, err)
+		}
+	}
+
+	expected := make([]string, 0, len(host.DNS.Expected))
+	for _, answer := range host.DNS.Expected {
+		expected = append(expected, strings.TrimSuffix(answer, "."))
+	}
+	slices.Sort(expected)
+
+	queryLatency := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "uptime_dns_query_latency",
+		Help: "The latency of the last DNS query, in milliseconds.",
+	})
+
+	answers := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "uptime_dns_answers",
+		Help: "The number of answers returned by the last DNS query.",
+	})
+
+	return &DNSChecker{
+		logger:        logger,
+		client:        &dns.Client{Timeout: time.Duration(host.Timeout) * time.Second},
+		resolver:      resolver,
+		name:          dns.Fqdn(u.Host),
+		record:        record,
+		expected:      expected,
+		expectedRegex: expectedRegex,
+		queryLatency:  queryLatency,
+		answers:       answers,
+	}, nil
+}
+
+// Check queries the resolver and validates the answers. The reported latency is the query time.
+func (c *DNSChecker) Check(ctx context.Context) CheckResult {
+	msg := new(dns.Msg)
+	msg.SetQuestion(c.name, c.record)
+
+	res, rtt, err := c.client.ExchangeContext(ctx, msg, c.resolver)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	c.queryLatency.Set(float64(rtt.Milliseconds()))
+
+	if res.Rcode != dns.RcodeSuccess {
+		c.answers.Set(0)
+		return CheckResult{
+			Reason: reasonDNSError,
+			Err:    fmt.Errorf("resolver answered [%s] for [%s]", dns.RcodeToString[res.Rcode], c.name),
+		}
+	}
+
+	answers := c.filterAnswers(res.Answer)
+	c.answers.Set(float64(len(answers)))
+	if len(answers) == 0 {
+		return CheckResult{
+			Reason: reasonDNSError,
+			Err:    fmt.Errorf("no %s record found for [%s]", dns.TypeToString[c.record], c.name),
+		}
+	}
+
+	if err := c.matchAnswers(answers); err != nil {
+		return CheckResult{Reason: reasonAssertionFailed, Err: err}
+	}
+
+	c.logger.Debugf("Resolved [%s] to %v. Counting as up.", c.name, answers)
+	return CheckResult{
+		Up:      true,
+		Latency: rtt,
+	}
+}
+
+// filterAnswers keeps the answers of the queried type and formats them as strings.
+func (c *DNSChecker) filterAnswers(records []dns.RR) []string {
+	var output []string
+	for _, rr := range records {
+		if rr.Header().Rrtype != c.record {
+			continue
+		}
+
+		switch r := rr.(type) {
+		case *dns.A:
+			output = append(output, r.A.String())
+		case *dns.AAAA:
+			output = append(output, r.AAAA.String())
+		case *dns.CNAME:
+			output = append(output, strings.TrimSuffix(r.Target, "."))
+		case *dns.MX:
+			output = append(output, strconv.Itoa(int(r.Preference))+" "+strings.TrimSuffix(r.Mx, "."))
+		case *dns.TXT:
+			output = append(output, strings.Join(r.Txt, ""))
+		case *dns.SRV:
+			output = append(output, fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, strings.TrimSuffix(r.Target, ".")))
+		}
+	}
+
+	return output
+}
+
+// matchAnswers validates the answers against the expected list and regex.
+func (c *DNSChecker) matchAnswers(answers []string) error {
+	if len(c.expected) > 0 {
+		sorted := slices.Clone(answers)
+		slices.Sort(sorted)
+		if !slices.Equal(sorted, c.expected) {
+			return fmt.Errorf("answers %v for [%s] do not match expected %v", answers, c.name, c.expected)
+		}
+	}
+
+	if c.expectedRegex != nil {
+		for _, answer := range answers {
+			if !c.expectedRegex.MatchString(answer) {
+				return fmt.Errorf("answer [%s] for [%s] does not match [%s]", answer, c.name, c.expectedRegex)
+			}
+		}
+	}
+
+	return nil
+}
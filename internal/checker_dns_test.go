@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"context"
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+)
+
+// setupDNSServer starts an in-process DNS server answering from the given records.
+func setupDNSServer(t *testing.T, records map[uint16][]string) string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start DNS server: %v", err)
+	}
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        pc,
+		NotifyStartedFunc: func() { close(started) },
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			res := new(dns.Msg)
+			res.SetReply(req)
+
+			question := req.Question[0]
+			if question.Name != "example.test." {
+				res.Rcode = dns.RcodeNameError
+			}
+
+			for _, record := range records[question.Qtype] {
+				rr, err := dns.NewRR(question.Name + " 60 IN " + dns.TypeToString[question.Qtype] + " " + record)
+				if err != nil {
+					t.Errorf("Invalid record [%s]: %v", record, err)
+					continue
+				}
+				res.Answer = append(res.Answer, rr)
+			}
+
+			_ = w.WriteMsg(res)
+		}),
+	}
+
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+	<-started
+
+	return pc.LocalAddr().String()
+}
+
+func setupDNSChecker(t *testing.T, name string, options DNSOptions) *DNSChecker {
+	checker, err := NewDNSChecker(Host{Host: "dns://" + name, Timeout: 1, DNS: options}, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create DNS checker: %v", err)
+	}
+
+	return checker
+}
+
+func TestDNSCheckerWithARecordExpectUp(t *testing.T) {
+	resolver := setupDNSServer(t, map[uint16][]string{
+		dns.TypeA: {"192.0.2.1", "192.0.2.2"},
+	})
+
+	checker := setupDNSChecker(t, "example.test", DNSOptions{Resolver: resolver})
+	result := checker.Check(context.Background())
+
+	assert.True(t, result.Up)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, float64(2), testutil.ToFloat64(checker.answers))
+}
+
+func TestDNSCheckerWithExpectedAnswersInAnyOrderExpectUp(t *testing.T) {
+	resolver := setupDNSServer(t, map[uint16][]string{
+		dns.TypeA: {"192.0.2.1", "192.0.2.2"},
+	})
+
+	checker := setupDNSChecker(t, "example.test", DNSOptions{
+		Resolver: resolver,
+		Expected: []string{"192.0.2.2", "192.0.2.1"},
+	})
+	result := checker.Check(context.Background())
+
+	assert.True(t, result.Up)
+}
+
+func TestDNSCheckerWithUnexpectedAnswersExpectDown(t *testing.T) {
+	resolver := setupDNSServer(t, map[uint16][]string{
+		dns.TypeA: {"192.0.2.1"},
+	})
+
+	checker := setupDNSChecker(t, "example.test", DNSOptions{
+		Resolver: resolver,
+		Expected: []string{"192.0.2.1", "192.0.2.2"},
+	})
+	result := checker.Check(context.Background())
+
+	assert.False(t, result.Up)
+	assert.Error(t, result.Err)
+}
+
+func TestDNSCheckerWithMXRecordAndRegexExpectUp(t *testing.T) {
+	resolver := setupDNSServer(t, map[uint16][]string{
+		dns.TypeMX: {"10 mx1.example.test.", "20 mx2.example.test."},
+	})
+
+	checker := setupDNSChecker(t, "example.test", DNSOptions{
+		Resolver:      resolver,
+		Record:        "mx",
+		ExpectedRegex: `^\d+ mx\d\.example\.test$`,
+	})
+	result := checker.Check(context.Background())
+
+	assert.True(t, result.Up)
+	assert.NoError(t, result.Err)
+}
+
+func TestDNSCheckerWithNXDomainExpectDown(t *testing.T) {
+	resolver := setupDNSServer(t, map[uint16][]string{})
+
+	checker := setupDNSChecker(t, "missing.test", DNSOptions{Resolver: resolver})
+	result := checker.Check(context.Background())
+
+	assert.False(t, result.Up)
+	assert.ErrorContains(t, result.Err, "NXDOMAIN")
+}
+
+func TestDNSCheckerWithNoAnswerExpectDown(t *testing.T) {
+	resolver := setupDNSServer(t, map[uint16][]string{
+		dns.TypeA: {"192.0.2.1"},
+	})
+
+	checker := setupDNSChecker(t, "example.test", DNSOptions{Resolver: resolver, Record: "AAAA"})
+	result := checker.Check(context.Background())
+
+	assert.False(t, result.Up)
+	assert.Equal(t, float64(0), testutil.ToFloat64(checker.answers))
+}
+
+func TestDNSCheckerWithUnreachableResolverExpectDown(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer pc.Close()
+
+	// the socket never answers, so the query times out
+	checker := setupDNSChecker(t, "example.test", DNSOptions{Resolver: pc.LocalAddr().String()})
+	result := checker.Check(context.Background())
+
+	assert.False(t, result.Up)
+	assert.Error(t, result.Err)
+}
+
+func TestNewDNSCheckerWithUnsupportedRecordExpectError(t *testing.T) {
+	_, err := NewDNSChecker(Host{Host: "dns://example.test", DNS: DNSOptions{Resolver: "127.0.0.1", Record: "PTR"}}, prometheus.NewRegistry())
+	assert.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCOptions holds the options specific to gRPC checks.
+type GRPCOptions struct {
+	// TLS enables TLS on the connection. The certificate authorities of the TLS options are trusted.
+	TLS bool `json:"tls,omitempty"`
+}
+
+// GRPCChecker is the implementation of the UptimeChecker interface for gRPC hosts (grpc://host:port/service).
+// It calls the standard grpc.health.v1.Health/Check RPC and only considers SERVING as up.
+type GRPCChecker struct {
+	logger        *logrus.Entry
+	conn          *grpc.ClientConn
+	client        healthpb.HealthClient
+	address       string
+	service       string
+	timeout       time.Duration
+	metadata      metadata.MD
+	servingStatus *prometheus.GaugeVec
+}
+
+// NewGRPCChecker creates a new GRPCChecker instance. The connection is established lazily on the first check.
+func NewGRPCChecker(host Host, registerer prometheus.Registerer) (*GRPCChecker, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"component": "grpc-checker",
+	})
+
+	u, err := url.Parse(host.Host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("grpc host [%s] must be in the form grpc://host:port/service", host.Host)
+	}
+
+	transportCredentials := insecure.NewCredentials()
+	if host.GRPC.TLS {
+		roots, err := loadCertPool(host.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificate authorities for [%s]: %w", host.Host, err)
+		}
+		transportCredentials = credentials.NewTLS(&tls.Config{RootCAs: roots})
+	}
+
+	conn, err := grpc.NewClient(u.Host, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return nil, err
+	}
+
+	servingStatus := promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "uptime_grpc_serving_status",
+		Help: "The serving status returned by the last health check. 1 for the current status, 0 for the others.",
+	}, []string{"status"})
+
+	return &GRPCChecker{
+		logger:        logger,
+		conn:          conn,
+		client:        healthpb.NewHealthClient(conn),
+		address:       u.Host,
+		service:       strings.TrimPrefix(u.Path, "/"),
+		timeout:       time.Duration(host.Timeout) * time.Second,
+		metadata:      metadata.New(host.Headers),
+		servingStatus: servingStatus,
+	}, nil
+}
+
+// Close closes the connection to the remote host.
+func (c *GRPCChecker) Close() error {
+	return c.conn.Close()
+}
+
+// Check calls the health checking RPC on the remote host. The reported latency is the duration of the call.
+func (c *GRPCChecker) Check(ctx context.Context) CheckResult {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	ctx = metadata.NewOutgoingContext(ctx, c.metadata)
+
+	start := time.Now()
+	res, err := c.client.Check(ctx, &healthpb.HealthCheckRequest{Service: c.service})
+	if err != nil {
+		c.setServingStatus(healthpb.HealthCheckResponse_UNKNOWN)
+		return CheckResult{Reason: grpcReason(err), Err: err}
+	}
+
+	latency := time.Since(start)
+	c.setServingStatus(res.GetStatus())
+
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return CheckResult{
+			Reason: reasonBadStatus,
+			Err:    fmt.Errorf("service [%s] on [%s] is [%s]", c.service, c.address, res.GetStatus()),
+		}
+	}
+
+	c.logger.Debugf("Service [%s] on [%s] is serving. Counting as up.", c.service, c.address)
+	return CheckResult{
+		Up:      true,
+		Latency: latency,
+	}
+}
+
+// grpcReason returns the reason matching the status of a failed RPC.
+func grpcReason(err error) string {
+	switch status.Code(err) {
+	case codes.DeadlineExceeded:
+		return reasonTimeout
+	case codes.NotFound:
+		// the service is unknown to the health server
+		return reasonBadStatus
+	default:
+		return classifyError(err)
+	}
+}
+
+// setServingStatus sets the serving status metric to 1 for the given status, and 0 for the others.
+func (c *GRPCChecker) setServingStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	for value, name := range healthpb.HealthCheckResponse_ServingStatus_name {
+		c.servingStatus.WithLabelValues(name).Set(boolToFloat(value == int32(status)))
+	}
+}
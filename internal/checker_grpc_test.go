@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"net"
+	"testing"
+)
+
+// setupGRPCServer starts a gRPC server exposing the health service. Incoming metadata is sent on the given channel.
+func setupGRPCServer(t *testing.T, received chan<- metadata.MD) (*health.Server, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start gRPC server: %v", err)
+	}
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if received != nil {
+			md, _ := metadata.FromIncomingContext(ctx)
+			received <- md
+		}
+		return handler(ctx, req)
+	}))
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return healthServer, listener.Addr().String()
+}
+
+func TestGRPCCheckerWithServingServiceExpectUp(t *testing.T) {
+	healthServer, address := setupGRPCServer(t, nil)
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+
+	checker, err := NewGRPCChecker(Host{Host: "grpc://" + address + "/orders", Timeout: 1}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.servingStatus.WithLabelValues("SERVING")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(checker.servingStatus.WithLabelValues("NOT_SERVING")))
+}
+
+func TestGRPCCheckerWithNotServingServiceExpectDown(t *testing.T) {
+	healthServer, address := setupGRPCServer(t, nil)
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	checker, err := NewGRPCChecker(Host{Host: "grpc://" + address + "/orders", Timeout: 1}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Error(t, result.Err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.servingStatus.WithLabelValues("NOT_SERVING")))
+}
+
+func TestGRPCCheckerWithUnknownServiceExpectDown(t *testing.T) {
+	_, address := setupGRPCServer(t, nil)
+
+	checker, err := NewGRPCChecker(Host{Host: "grpc://" + address + "/missing", Timeout: 1}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Error(t, result.Err)
+}
+
+func TestGRPCCheckerSendsHeadersAsMetadata(t *testing.T) {
+	received := make(chan metadata.MD, 1)
+	_, address := setupGRPCServer(t, received)
+
+	checker, err := NewGRPCChecker(Host{
+		Host:    "grpc://" + address,
+		Timeout: 1,
+		Headers: map[string]string{
+			"Authorization": "Bearer 123",
+		},
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+	assert.Equal(t, []string{"Bearer 123"}, (<-received).Get("authorization"))
+}
@@ -0,0 +1,340 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+// maxBodySize is the maximum number of bytes of a response body read on each check.
+const maxBodySize = 10 << 20
+
+// defaultMaxRedirects is the maximum number of redirects followed when none is configured.
+const defaultMaxRedirects = 10
+
+// Redirect policies of HTTP checks.
+const (
+	redirectsFollow = "follow"
+	redirectsNone   = "none"
+)
+
+// HTTPOptions holds the options specific to HTTP checks.
+type HTTPOptions struct {
+	// Method is the method of the request. Defaults to GET.
+	Method string `json:"method,omitempty"`
+	// Body is the body of the request.
+	Body string `json:"body,omitempty"`
+	// BodyFile is a file to read the body of the request from, used when Body is empty.
+	BodyFile string `json:"body_file,omitempty"`
+	// ContentType is the content type of the request body.
+	ContentType string `json:"content_type,omitempty"`
+	// StatusCodes lists the accepted status codes, as codes (401), classes (3xx) or ranges (200-204). Defaults to 2xx.
+	StatusCodes []string `json:"status_codes,omitempty"`
+	// Redirects is the redirect policy, either follow or none. Defaults to follow.
+	Redirects string `json:"redirects,omitempty"`
+	// MaxRedirects is the maximum number of redirects followed. Defaults to 10.
+	MaxRedirects int `json:"max_redirects,omitempty"`
+	// FinalURL is the URL the request must end on once the redirects are followed.
+	FinalURL string `json:"final_url,omitempty"`
+}
+
+// statusCodeRange is an inclusive range of accepted status codes.
+type statusCodeRange struct {
+	from, to int
+}
+
+// parseStatusCodes parses the accepted status codes. It accepts codes (401), classes (3xx) and ranges (200-204).
+func parseStatusCodes(entries []string) ([]statusCodeRange, error) {
+	if len(entries) == 0 {
+		return []statusCodeRange{{200, 299}}, nil
+	}
+
+	var output []statusCodeRange
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+
+		if len(entry) == 3 && strings.HasSuffix(entry, "xx") && entry[0] >= '1' && entry[0] <= '5' {
+			class := int(entry[0]-'0') * 100
+			output = append(output, statusCodeRange{class, class + 99})
+			continue
+		}
+
+		from, to, isRange := strings.Cut(entry, "-")
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code [%s]", entry)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(to)
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid status code range [%s]", entry)
+			}
+		}
+
+		output = append(output, statusCodeRange{start, end})
+	}
+
+	return output, nil
+}
+
+// HTTPChecker is the implementation of the UptimeChecker interface for HTTP and HTTPS hosts.
+type HTTPChecker struct {
+	logger       *logrus.Entry
+	httpClient   *http.Client
+	host         string
+	certificates *certificateMetrics
+	assertions   []bodyAssertion
+	method       string
+	body         []byte
+	contentType  string
+	statusCodes  []statusCodeRange
+	finalURL     string
+	phases       *prometheus.GaugeVec
+}
+
+// NewHTTPChecker creates a new HTTPChecker instance. For HTTPS hosts, the certificate metrics
+// are registered on the given registerer.
+func NewHTTPChecker(host Host, registerer prometheus.Registerer) (*HTTPChecker, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"component": "http-checker",
+	})
+
+	// create a cookie jar to store cookies
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		logger.Fatalf("Failed to create cookie jar: %v", err)
+		return nil, err
+	}
+
+	u, err := url.Parse(host.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	assertions, err := compileAssertions(host.Assertions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile assertions for [%s]: %w", host.Host, err)
+	}
+
+	statusCodes, err := parseStatusCodes(host.HTTP.StatusCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse status codes for [%s]: %w", host.Host, err)
+	}
+
+	body := []byte(host.HTTP.Body)
+	if len(body) == 0 && host.HTTP.BodyFile != "" {
+		body, err = os.ReadFile(host.HTTP.BodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body file for [%s]: %w", host.Host, err)
+		}
+	}
+
+	method := strings.ToUpper(host.HTTP.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	checkRedirect, err := redirectPolicy(host.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect policy for [%s]: %w", host.Host, err)
+	}
+
+	// connections are not reused, so that each check measures every phase of the request
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+
+	var certificates *certificateMetrics
+	if u.Scheme == "https" {
+		roots, err := loadCertPool(host.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificate authorities for [%s]: %w", host.Host, err)
+		}
+
+		certificates = newCertificateMetrics(host.TLS, roots, registerer)
+		transport.TLSClientConfig = &tls.Config{
+			// the certificate is verified by certificateMetrics, to report on it even when it's invalid
+			InsecureSkipVerify: true,
+			VerifyConnection:   certificates.verifyConnection(u.Hostname()),
+		}
+	}
+
+	phases := promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "uptime_http_phase_latency",
+		Help: "The duration of each phase of the last request (dns, connect, tls, first_byte, transfer), in milliseconds.",
+	}, []string{"phase"})
+
+	httpClient := &http.Client{
+		Timeout: time.Duration(host.Timeout) * time.Second,
+		Transport: &headerRoundTripper{
+			headers: host.Headers,
+			rt:      transport,
+		},
+		Jar:           jar,
+		CheckRedirect: checkRedirect,
+	}
+
+	return &HTTPChecker{
+		logger:       logger,
+		httpClient:   httpClient,
+		host:         host.Host,
+		certificates: certificates,
+		assertions:   assertions,
+		method:       method,
+		body:         body,
+		contentType:  host.HTTP.ContentType,
+		statusCodes:  statusCodes,
+		finalURL:     host.HTTP.FinalURL,
+		phases:       phases,
+	}, nil
+}
+
+// redirectPolicy returns the function deciding whether a redirect is followed, according to the options.
+func redirectPolicy(options HTTPOptions) (func(req *http.Request, via []*http.Request) error, error) {
+	switch options.Redirects {
+	case redirectsNone:
+		return func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}, nil
+	case "", redirectsFollow:
+		maxRedirects := options.MaxRedirects
+		if maxRedirects <= 0 {
+			maxRedirects = defaultMaxRedirects
+		}
+
+		return func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		}, nil
+	default:
+		return nil, errors.New("redirects must be either follow or none")
+	}
+}
+
+// observeTimings sets the phase metrics from the timings of the last request.
+func (c *HTTPChecker) observeTimings(timings *httpTimings) {
+	for _, phase := range httpPhases {
+		c.phases.WithLabelValues(phase).Set(durationToMilliseconds(timings.get(phase)))
+	}
+}
+
+// acceptsStatusCode returns whether the status code is one of the accepted ones.
+func (c *HTTPChecker) acceptsStatusCode(statusCode int) bool {
+	for _, accepted := range c.statusCodes {
+		if statusCode >= accepted.from && statusCode <= accepted.to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check performs the configured request on the remote host. Only the accepted status codes (2xx by default)
+// are considered as up, and the body must satisfy the assertions of the host.
+func (c *HTTPChecker) Check(ctx context.Context) CheckResult {
+	timings := newHTTPTimings()
+	defer c.observeTimings(timings)
+	ctx = httptrace.WithClientTrace(ctx, timings.clientTrace())
+
+	start := time.Now()
+
+	var requestBody io.Reader
+	if len(c.body) > 0 {
+		requestBody = bytes.NewReader(c.body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, c.method, c.host, requestBody)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	if c.contentType != "" {
+		req.Header.Set("Content-Type", c.contentType)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	latency := time.Since(start)
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return CheckResult{StatusCode: res.StatusCode, Err: fmt.Errorf("failed to read body: %w", err)}
+	}
+	timings.bodyRead()
+
+	// if the status code is not accepted, we consider the host as down
+	if !c.acceptsStatusCode(res.StatusCode) {
+		c.logger.Warnf("Got status code [%d] for [%s]. Counting as down.", res.StatusCode, c.host)
+		return CheckResult{
+			StatusCode: res.StatusCode,
+			Reason:     reasonBadStatus,
+			Err:        fmt.Errorf("unexpected status code [%d]", res.StatusCode),
+		}
+	}
+
+	if c.finalURL != "" && res.Request.URL.String() != c.finalURL {
+		err := &AssertionError{
+			Assertion: fmt.Sprintf("final url %q", c.finalURL),
+			Err:       fmt.Errorf("ended on [%s]", res.Request.URL),
+		}
+		c.logger.Warnf("Got %v for [%s]. Counting as down.", err, c.host)
+		return CheckResult{
+			StatusCode: res.StatusCode,
+			Reason:     reasonAssertionFailed,
+			Err:        err,
+		}
+	}
+
+	if err := runAssertions(c.assertions, body); err != nil {
+		c.logger.Warnf("Got %v for [%s]. Counting as down.", err, c.host)
+		return CheckResult{
+			StatusCode: res.StatusCode,
+			Reason:     reasonAssertionFailed,
+			Err:        err,
+		}
+	}
+
+	c.logger.Debugf("Got status code [%d] for [%s]. Counting as up.", res.StatusCode, c.host)
+	return CheckResult{
+		Up:         true,
+		Latency:    latency,
+		StatusCode: res.StatusCode,
+	}
+}
+
+// headerRoundTripper is a custom RoundTripper that adds headers to each request.
+type headerRoundTripper struct {
+	headers map[string]string
+	rt      http.RoundTripper
+}
+
+// RoundTrip executes a single HTTP transaction. It will add the headers to the request before sending it.
+func (hrt *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	for key, value := range hrt.headers {
+		req.Header.Set(key, value)
+	}
+
+	return hrt.rt.RoundTrip(req)
+}
@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"context"
+	"encoding/pem"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setupHTTPChecker(t *testing.T, host Host) *HTTPChecker {
+	checker, err := NewHTTPChecker(host, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create HTTP checker: %v", err)
+	}
+
+	return checker
+}
+
+func TestHTTPCheckerWithPostRequestExpectMethodAndBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" || string(body) != `{"ping":true}` {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	checker := setupHTTPChecker(t, Host{
+		Host: server.URL,
+		HTTP: HTTPOptions{
+			Method:      "post",
+			Body:        `{"ping":true}`,
+			ContentType: "application/json",
+		},
+	})
+
+	// the body is sent again on each check
+	for i := 0; i < 2; i++ {
+		result := checker.Check(context.Background())
+		assert.True(t, result.Up)
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+	}
+}
+
+func TestHTTPCheckerWithBodyFileExpectBodySent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "query { health }" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	bodyFile := filepath.Join(t.TempDir(), "body.graphql")
+	assert.NoError(t, os.WriteFile(bodyFile, []byte("query { health }"), 0o600))
+
+	checker := setupHTTPChecker(t, Host{
+		Host: server.URL,
+		HTTP: HTTPOptions{Method: http.MethodPost, BodyFile: bodyFile},
+	})
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+}
+
+func TestHTTPCheckerWithAcceptedUnauthorizedStatusExpectUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	checker := setupHTTPChecker(t, Host{
+		Host: server.URL,
+		HTTP: HTTPOptions{StatusCodes: []string{"2xx", "401"}},
+	})
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+	assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+}
+
+func TestHTTPCheckerWithoutFollowingRedirectsExpectRedirectStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login", http.StatusFound)
+	}))
+	defer server.Close()
+
+	checker := setupHTTPChecker(t, Host{
+		Host: server.URL,
+		HTTP: HTTPOptions{Redirects: redirectsNone, StatusCodes: []string{"3xx"}},
+	})
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+
+	// the redirect is not accepted by default
+	checker = setupHTTPChecker(t, Host{
+		Host: server.URL,
+		HTTP: HTTPOptions{Redirects: redirectsNone},
+	})
+
+	result = checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Equal(t, reasonBadStatus, result.Reason)
+}
+
+func TestHTTPCheckerWithTooManyRedirectsExpectDown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	}))
+	defer server.Close()
+
+	checker := setupHTTPChecker(t, Host{
+		Host: server.URL + "/",
+		HTTP: HTTPOptions{MaxRedirects: 2},
+	})
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.ErrorContains(t, result.Err, "stopped after 2 redirects")
+}
+
+func TestHTTPCheckerWithFinalURLExpectAssertion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/home", http.StatusMovedPermanently)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := setupHTTPChecker(t, Host{
+		Host: server.URL + "/",
+		HTTP: HTTPOptions{FinalURL: server.URL + "/home"},
+	})
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+
+	checker = setupHTTPChecker(t, Host{
+		Host: server.URL + "/",
+		HTTP: HTTPOptions{FinalURL: server.URL + "/login"},
+	})
+	result = checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Equal(t, reasonAssertionFailed, result.Reason)
+}
+
+func TestParseStatusCodes(t *testing.T) {
+	ranges, err := parseStatusCodes([]string{"200", "3xx", "401-403"})
+	assert.NoError(t, err)
+	assert.Equal(t, []statusCodeRange{{200, 200}, {300, 399}, {401, 403}}, ranges)
+
+	ranges, err = parseStatusCodes(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []statusCodeRange{{200, 299}}, ranges)
+
+	for _, invalid := range []string{"abc", "9xx", "404-400", "200-"} {
+		_, err := parseStatusCodes([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestNewHTTPCheckerWithInvalidRedirectPolicyExpectError(t *testing.T) {
+	_, err := NewHTTPChecker(Host{Host: "http://example.com", HTTP: HTTPOptions{Redirects: "sometimes"}}, prometheus.NewRegistry())
+	assert.Error(t, err)
+}
+
+func TestHTTPCheckerWithHTTPSHostExpectPhaseTimings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(caFile, content, 0o600))
+
+	checker := setupHTTPChecker(t, Host{Host: server.URL, TLS: TLSOptions{CAFile: caFile}})
+
+	// each check opens a new connection, so every phase is measured on each check
+	for i := 0; i < 2; i++ {
+		result := checker.Check(context.Background())
+		assert.True(t, result.Up)
+
+		assert.Greater(t, testutil.ToFloat64(checker.phases.WithLabelValues(phaseConnect)), float64(0))
+		assert.Greater(t, testutil.ToFloat64(checker.phases.WithLabelValues(phaseTLS)), float64(0))
+		assert.GreaterOrEqual(t, testutil.ToFloat64(checker.phases.WithLabelValues(phaseFirstByte)), float64(50))
+		assert.GreaterOrEqual(t, testutil.ToFloat64(checker.phases.WithLabelValues(phaseTransfer)), float64(50))
+	}
+}
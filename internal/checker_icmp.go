@@ -0,0 +1,268 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// defaultPacketCount is the number of echo requests sent on each ICMP check when none is configured.
+const defaultPacketCount = 3
+
+// ICMPOptions holds the options specific to ICMP checks.
+type ICMPOptions struct {
+	// Count is the number of echo requests sent on each check. Defaults to 3.
+	Count int `json:"count,omitempty"`
+}
+
+// ICMPChecker is the implementation of the UptimeChecker interface for ICMP hosts (icmp://host).
+// It sends echo requests and reports the packet loss and round-trip times.
+type ICMPChecker struct {
+	logger     *logrus.Entry
+	address    string
+	count      int
+	timeout    time.Duration
+	id         int
+	packetLoss prometheus.Gauge
+	rtt        *prometheus.GaugeVec
+	jitter     prometheus.Gauge
+}
+
+// NewICMPChecker creates a new ICMPChecker instance.
+func NewICMPChecker(host Host, registerer prometheus.Registerer) (*ICMPChecker, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"component": "icmp-checker",
+	})
+
+	u, err := url.Parse(host.Host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("icmp host [%s] must be in the form icmp://host", host.Host)
+	}
+
+	count := host.ICMP.Count
+	if count <= 0 {
+		count = defaultPacketCount
+	}
+
+	// each echo request waits for a share of the timeout, to keep the whole check within it
+	timeout := time.Second
+	if host.Timeout > 0 {
+		timeout = time.Duration(host.Timeout) * time.Second / time.Duration(count)
+	}
+
+	packetLoss := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "uptime_icmp_packet_loss",
+		Help: "The ratio of echo requests without reply during the last check, between 0 and 1.",
+	})
+
+	rtt := promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "uptime_icmp_rtt",
+		Help: "The round-trip time of the echo requests of the last check, in milliseconds.",
+	}, []string{"stat"})
+
+	jitter := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "uptime_icmp_jitter",
+		Help: "The mean deviation between consecutive round-trip times of the last check, in milliseconds.",
+	})
+
+	return &ICMPChecker{
+		logger:     logger,
+		address:    u.Hostname(),
+		count:      count,
+		timeout:    timeout,
+		id:         rand.IntN(math.MaxUint16),
+		packetLoss: packetLoss,
+		rtt:        rtt,
+		jitter:     jitter,
+	}, nil
+}
+
+// Check sends the echo requests to the remote host. The host is up if at least one reply is received,
+// and the reported latency is the average round-trip time.
+func (c *ICMPChecker) Check(ctx context.Context) CheckResult {
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, c.address)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	if len(ips) == 0 {
+		return CheckResult{Reason: reasonDNSError, Err: fmt.Errorf("no address found for [%s]", c.address)}
+	}
+	ip := ips[0].IP
+
+	conn, privileged, err := listenICMP(ip)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	var destination net.Addr = &net.IPAddr{IP: ip}
+	if !privileged {
+		destination = &net.UDPAddr{IP: ip}
+	}
+
+	var rtts []time.Duration
+	for seq := 0; seq < c.count; seq++ {
+		if ctx.Err() != nil {
+			break
+		}
+
+		rtt, err := c.ping(conn, destination, ip, privileged, seq)
+		if err != nil {
+			c.logger.Debugf("No reply to echo request [%d] for [%s]: %v", seq, c.address, err)
+			continue
+		}
+		rtts = append(rtts, rtt)
+	}
+
+	c.observe(rtts)
+	if len(rtts) == 0 {
+		return CheckResult{
+			Reason: reasonTimeout,
+			Err:    fmt.Errorf("no reply received from [%s] for [%d] echo requests", c.address, c.count),
+		}
+	}
+
+	c.logger.Debugf("Received [%d/%d] echo replies from [%s]. Counting as up.", len(rtts), c.count, c.address)
+	return CheckResult{
+		Up:      true,
+		Latency: average(rtts),
+	}
+}
+
+// observe sets the packet loss, round-trip time and jitter metrics from the round-trip times of the replies.
+// When no reply is received, the round-trip times are removed and the jitter is set to NaN, so that the
+// latency of the last replies is not reported while the host is down.
+func (c *ICMPChecker) observe(rtts []time.Duration) {
+	c.packetLoss.Set(float64(c.count-len(rtts)) / float64(c.count))
+	if len(rtts) == 0 {
+		c.rtt.Reset()
+		c.jitter.Set(math.NaN())
+		return
+	}
+
+	minimum, maximum, deviation := rtts[0], rtts[0], time.Duration(0)
+	for i, rtt := range rtts {
+		minimum = min(minimum, rtt)
+		maximum = max(maximum, rtt)
+		if i > 0 {
+			deviation += (rtt - rtts[i-1]).Abs()
+		}
+	}
+
+	c.rtt.WithLabelValues("min").Set(durationToMilliseconds(minimum))
+	c.rtt.WithLabelValues("avg").Set(durationToMilliseconds(average(rtts)))
+	c.rtt.WithLabelValues("max").Set(durationToMilliseconds(maximum))
+	if len(rtts) > 1 {
+		c.jitter.Set(durationToMilliseconds(deviation / time.Duration(len(rtts)-1)))
+	} else {
+		c.jitter.Set(0)
+	}
+}
+
+// average returns the mean of the round-trip times, which must not be empty.
+func average(rtts []time.Duration) time.Duration {
+	var total time.Duration
+	for _, rtt := range rtts {
+		total += rtt
+	}
+
+	return total / time.Duration(len(rtts))
+}
+
+// ping sends a single echo request and waits for the matching reply.
+func (c *ICMPChecker) ping(conn *icmp.PacketConn, destination net.Addr, ip net.IP, privileged bool, seq int) (time.Duration, error) {
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	protocol := 1
+	if ip.To4() == nil {
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+		protocol = 58
+	}
+
+	request, err := (&icmp.Message{
+		Type: requestType,
+		Body: &icmp.Echo{
+			ID:   c.id,
+			Seq:  seq,
+			Data: []byte("uptimer"),
+		},
+	}).Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	if err := conn.SetDeadline(start.Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	if _, err := conn.WriteTo(request, destination); err != nil {
+		return 0, err
+	}
+
+	buffer := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return 0, err
+		}
+
+		reply, err := icmp.ParseMessage(protocol, buffer[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq {
+			continue
+		}
+
+		// raw sockets receive every reply of the host, unlike datagram sockets which are bound by the kernel
+		if privileged && (echo.ID != c.id || peer.String() != ip.String()) {
+			continue
+		}
+
+		return time.Since(start), nil
+	}
+}
+
+// listenICMP opens an unprivileged datagram ICMP socket, falling back to a raw socket when datagram
+// sockets are not allowed (see net.ipv4.ping_group_range). It reports whether the socket is raw.
+func listenICMP(ip net.IP) (*icmp.PacketConn, bool, error) {
+	network, rawNetwork, address := "udp4", "ip4:icmp", "0.0.0.0"
+	if ip.To4() == nil {
+		network, rawNetwork, address = "udp6", "ip6:ipv6-icmp", "::"
+	}
+
+	conn, err := icmp.ListenPacket(network, address)
+	if err == nil {
+		return conn, false, nil
+	}
+
+	conn, rawErr := icmp.ListenPacket(rawNetwork, address)
+	if rawErr == nil {
+		return conn, true, nil
+	}
+
+	return nil, false, fmt.Errorf("failed to open an ICMP socket: %w", errors.Join(err, rawErr))
+}
+
+// durationToMilliseconds converts a duration to a number of milliseconds, keeping sub-millisecond precision.
+func durationToMilliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+// skipWithoutICMP skips the test when neither datagram nor raw ICMP sockets can be opened.
+func skipWithoutICMP(t *testing.T) {
+	conn, _, err := listenICMP(net.IPv4(127, 0, 0, 1))
+	if err != nil {
+		t.Skipf("ICMP sockets are not available: %v", err)
+	}
+	_ = conn.Close()
+}
+
+func TestICMPCheckerWithLoopbackExpectUp(t *testing.T) {
+	skipWithoutICMP(t)
+
+	checker, err := NewICMPChecker(Host{
+		Host:    "icmp://127.0.0.1",
+		Timeout: 3,
+		ICMP:    ICMPOptions{Count: 3},
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, float64(0), testutil.ToFloat64(checker.packetLoss))
+	assert.Greater(t, testutil.ToFloat64(checker.rtt.WithLabelValues("max")), float64(0))
+	assert.LessOrEqual(t,
+		testutil.ToFloat64(checker.rtt.WithLabelValues("min")),
+		testutil.ToFloat64(checker.rtt.WithLabelValues("avg")),
+	)
+}
+
+func TestICMPCheckerWithUnresolvableHostExpectDown(t *testing.T) {
+	checker, err := NewICMPChecker(Host{Host: "icmp://host.invalid", Timeout: 1}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Error(t, result.Err)
+}
+
+func TestNewICMPCheckerWithDefaultCount(t *testing.T) {
+	checker, err := NewICMPChecker(Host{Host: "icmp://127.0.0.1"}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+	assert.Equal(t, defaultPacketCount, checker.count)
+}
+
+func TestICMPCheckerObserveWithTotalLossExpectLatencyCleared(t *testing.T) {
+	checker, err := NewICMPChecker(Host{Host: "icmp://127.0.0.1", ICMP: ICMPOptions{Count: 3}}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	checker.observe([]time.Duration{time.Millisecond, 3 * time.Millisecond})
+	assert.InDelta(t, 1.0/3, testutil.ToFloat64(checker.packetLoss), 0.001)
+	assert.Equal(t, float64(2), testutil.ToFloat64(checker.rtt.WithLabelValues("avg")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(checker.jitter))
+
+	checker.observe(nil)
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.packetLoss))
+	assert.Zero(t, testutil.CollectAndCount(checker.rtt))
+	assert.True(t, math.IsNaN(testutil.ToFloat64(checker.jitter)))
+}
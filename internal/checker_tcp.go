@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxBannerSize is the maximum number of bytes read from a TCP connection when matching a banner.
+const maxBannerSize = 4096
+
+// TCPOptions holds the options specific to TCP checks.
+type TCPOptions struct {
+	// Send is an optional payload written to the connection once it is established.
+	Send string `json:"send,omitempty"`
+	// Expect is an optional regular expression the data read from the connection must match.
+	Expect string `json:"expect,omitempty"`
+}
+
+// TCPChecker is the implementation of the UptimeChecker interface for raw TCP hosts (tcp://host:port).
+// It measures the time needed to establish the connection and can optionally match a banner.
+type TCPChecker struct {
+	logger  *logrus.Entry
+	address string
+	timeout time.Duration
+	send    []byte
+	expect  *regexp.Regexp
+}
+
+// NewTCPChecker creates a new TCPChecker instance.
+func NewTCPChecker(host Host) (*TCPChecker, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"component": "tcp-checker",
+	})
+
+	u, err := url.Parse(host.Host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("tcp host [%s] must be in the form tcp://host:port", host.Host)
+	}
+
+	var expect *regexp.Regexp
+	if host.TCP.Expect != "" {
+		expect, err = regexp.Compile(host.TCP.Expect)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expected banner for [%s]: %w", host.Host, err)
+		}
+	}
+
+	return &TCPChecker{
+		logger:  logger,
+		address: u.Host,
+		timeout: time.Duration(host.Timeout) * time.Second,
+		send:    []byte(host.TCP.Send),
+		expect:  expect,
+	}, nil
+}
+
+// Check opens a TCP connection to the remote host. The reported latency is the connect time.
+func (c *TCPChecker) Check(ctx context.Context) CheckResult {
+	start := time.Now()
+
+	dialer := &net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	latency := time.Since(start)
+
+	if c.timeout > 0 {
+		if err := conn.SetDeadline(start.Add(c.timeout)); err != nil {
+			return CheckResult{Err: err}
+		}
+	}
+
+	if len(c.send) > 0 {
+		if _, err := conn.Write(c.send); err != nil {
+			return CheckResult{Err: fmt.Errorf("failed to send payload: %w", err)}
+		}
+	}
+
+	if c.expect != nil {
+		if err := c.matchBanner(conn); err != nil {
+			reason := reasonAssertionFailed
+			if classifyError(err) == reasonTimeout {
+				reason = reasonTimeout
+			}
+
+			return CheckResult{Reason: reason, Err: err}
+		}
+	}
+
+	c.logger.Debugf("Connected to [%s] in [%s]. Counting as up.", c.address, latency)
+	return CheckResult{
+		Up:      true,
+		Latency: latency,
+	}
+}
+
+// matchBanner reads from the connection until the expected pattern matches, the connection is closed
+// or the read deadline is reached.
+func (c *TCPChecker) matchBanner(conn net.Conn) error {
+	banner := make([]byte, 0, maxBannerSize)
+	buffer := make([]byte, 512)
+
+	for len(banner) < maxBannerSize {
+		n, err := conn.Read(buffer)
+		banner = append(banner, buffer[:n]...)
+		if c.expect.Match(banner) {
+			return nil
+		}
+
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return fmt.Errorf("timed out waiting for banner matching [%s]: %w", c.expect, err)
+			}
+
+			return fmt.Errorf("banner [%q] does not match [%s]: %w", banner, c.expect, err)
+		}
+	}
+
+	return fmt.Errorf("banner [%q] does not match [%s]", banner, c.expect)
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+)
+
+// setupTCPServer starts a TCP server that answers each connection with the given handler.
+func setupTCPServer(t *testing.T, handler func(conn net.Conn)) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start TCP server: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				handler(conn)
+			}()
+		}
+	}()
+
+	return listener
+}
+
+func TestTCPCheckerWithOpenPortExpectUp(t *testing.T) {
+	listener := setupTCPServer(t, func(conn net.Conn) {})
+	defer listener.Close()
+
+	checker, err := NewTCPChecker(Host{Host: "tcp://" + listener.Addr().String(), Timeout: 1})
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+	assert.NoError(t, result.Err)
+	assert.NotZero(t, result.Latency)
+}
+
+func TestTCPCheckerWithClosedPortExpectDown(t *testing.T) {
+	listener := setupTCPServer(t, func(conn net.Conn) {})
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	checker, err := NewTCPChecker(Host{Host: "tcp://" + address, Timeout: 1})
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Error(t, result.Err)
+}
+
+func TestTCPCheckerWithMatchingBannerExpectUp(t *testing.T) {
+	listener := setupTCPServer(t, func(conn net.Conn) {
+		buffer := make([]byte, 6)
+		_, _ = conn.Read(buffer)
+		if string(buffer) == "PING\r\n" {
+			_, _ = conn.Write([]byte("+PONG\r\n"))
+		}
+	})
+	defer listener.Close()
+
+	checker, err := NewTCPChecker(Host{
+		Host:    "tcp://" + listener.Addr().String(),
+		Timeout: 1,
+		TCP: TCPOptions{
+			Send:   "PING\r\n",
+			Expect: `^\+PONG`,
+		},
+	})
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+	assert.NoError(t, result.Err)
+}
+
+func TestTCPCheckerWithMismatchingBannerExpectDown(t *testing.T) {
+	listener := setupTCPServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+	})
+	defer listener.Close()
+
+	checker, err := NewTCPChecker(Host{
+		Host:    "tcp://" + listener.Addr().String(),
+		Timeout: 1,
+		TCP: TCPOptions{
+			Expect: "^220 ",
+		},
+	})
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Error(t, result.Err)
+}
+
+func TestNewTCPCheckerWithoutPortExpectError(t *testing.T) {
+	_, err := NewTCPChecker(Host{Host: "tcp://localhost"})
+	assert.Error(t, err)
+}
+
+func TestSeekerWithTCPHostExpectUp(t *testing.T) {
+	listener := setupTCPServer(t, func(conn net.Conn) {})
+	defer listener.Close()
+
+	seeker, err := NewSeeker(
+		Host{Host: "tcp://" + listener.Addr().String(), Timeout: 1, Interval: 60},
+		prometheus.NewRegistry(),
+	)
+	assert.NoError(t, err)
+
+	seeker.check(context.Background())
+	assert.Equal(t, float64(1), testutil.ToFloat64(seeker.up))
+}
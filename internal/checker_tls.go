@@ -0,0 +1,281 @@
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultWarnDays is the number of days before expiry at which a certificate is reported as expiring.
+const defaultWarnDays = 14
+
+// TLSOptions holds the options related to TLS, used by HTTPS and TLS checks.
+type TLSOptions struct {
+	// WarnDays is the number of days before expiry at which the certificate is reported as expiring.
+	WarnDays int `json:"warn_days,omitempty"`
+	// CAFile is an optional PEM bundle of certificate authorities to trust instead of the system ones.
+	CAFile string `json:"ca_file,omitempty"`
+}
+
+// loadCertPool loads the certificate authorities from a PEM file. It returns nil when no file is
+// provided, in which case the system pool is used.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("no certificate found in [%s]", path)
+	}
+
+	return pool, nil
+}
+
+// certificateMetrics inspects the certificates presented by a remote host and exposes their state.
+type certificateMetrics struct {
+	logger      *logrus.Entry
+	roots       *x509.CertPool
+	warnAfter   time.Duration
+	expiry      prometheus.Gauge
+	notAfter    prometheus.Gauge
+	sanMatch    prometheus.Gauge
+	chainValid  prometheus.Gauge
+	expiring    prometheus.Gauge
+	info        *prometheus.GaugeVec
+	wasExpiring bool
+}
+
+// newCertificateMetrics creates a new certificateMetrics instance and registers its metrics.
+func newCertificateMetrics(options TLSOptions, roots *x509.CertPool, registerer prometheus.Registerer) *certificateMetrics {
+	warnDays := options.WarnDays
+	if warnDays <= 0 {
+		warnDays = defaultWarnDays
+	}
+
+	return &certificateMetrics{
+		logger: logrus.WithFields(logrus.Fields{
+			"component": "certificates",
+		}),
+		roots:     roots,
+		warnAfter: time.Duration(warnDays) * 24 * time.Hour,
+		expiry: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "uptime_tls_cert_expiry_seconds",
+			Help: "The number of seconds until the leaf certificate expires. Negative once expired.",
+		}),
+		notAfter: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "uptime_tls_cert_not_after",
+			Help: "The expiry date of the leaf certificate, as a unix timestamp.",
+		}),
+		sanMatch: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "uptime_tls_cert_san_match",
+			Help: "Whether the leaf certificate is valid for the host name or not.",
+		}),
+		chainValid: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "uptime_tls_cert_chain_valid",
+			Help: "Whether the certificate chain is trusted and not expired or not.",
+		}),
+		expiring: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "uptime_tls_cert_expiring",
+			Help: "Whether the leaf certificate expires within the warning period or not.",
+		}),
+		info: promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "uptime_tls_cert_info",
+			Help: "Information about the leaf certificate. Always 1.",
+		}, []string{"subject", "issuer"}),
+	}
+}
+
+// observe updates the metrics from the state of a TLS connection. It returns an error when the
+// certificate is expired, not valid for the server name, or not trusted.
+func (m *certificateMetrics) observe(state *tls.ConnectionState, serverName string) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no certificate presented by the remote host")
+	}
+
+	now := time.Now()
+	leaf := state.PeerCertificates[0]
+	remaining := leaf.NotAfter.Sub(now)
+
+	m.expiry.Set(remaining.Seconds())
+	m.notAfter.Set(float64(leaf.NotAfter.Unix()))
+	m.info.Reset()
+	m.info.WithLabelValues(leaf.Subject.String(), leaf.Issuer.String()).Set(1)
+
+	expiring := remaining < m.warnAfter
+	m.expiring.Set(boolToFloat(expiring))
+	if expiring && !m.wasExpiring {
+		m.logger.Warnf("Certificate for [%s] expires on [%s].", serverName, leaf.NotAfter.Format(time.RFC3339))
+	}
+	m.wasExpiring = expiring
+
+	sanErr := leaf.VerifyHostname(serverName)
+	m.sanMatch.Set(boolToFloat(sanErr == nil))
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, chainErr := leaf.Verify(x509.VerifyOptions{
+		Roots:         m.roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	m.chainValid.Set(boolToFloat(chainErr == nil))
+
+	switch {
+	case remaining <= 0:
+		return fmt.Errorf("certificate for [%s] expired on [%s]", serverName, leaf.NotAfter.Format(time.RFC3339))
+	case sanErr != nil:
+		return sanErr
+	case chainErr != nil:
+		return chainErr
+	}
+
+	return nil
+}
+
+// verifyConnection returns the function verifying the TLS connections of an HTTP client in place of the
+// client, which must skip its own verification. The certificate of the host is observed, so that its
+// metrics are updated even when it is invalid, and the certificates of the hosts it redirects to are
+// verified as the client would.
+func (m *certificateMetrics) verifyConnection(serverName string) func(tls.ConnectionState) error {
+	// the server name sent in the handshake, which is empty for IP addresses
+	handshakeName := strings.TrimSuffix(serverName, ".")
+	if net.ParseIP(serverName) != nil {
+		handshakeName = ""
+	}
+
+	return func(state tls.ConnectionState) error {
+		var err error
+		if state.ServerName == handshakeName {
+			err = m.observe(&state, serverName)
+		} else {
+			err = verifyChain(state, m.roots)
+		}
+		if err != nil {
+			return &tls.CertificateVerificationError{UnverifiedCertificates: state.PeerCertificates, Err: err}
+		}
+
+		return nil
+	}
+}
+
+// verifyChain verifies that the certificate of a connection is trusted and valid for its server name.
+func verifyChain(state tls.ConnectionState, roots *x509.CertPool) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no certificate presented by the remote host")
+	}
+	if state.ServerName == "" {
+		return errors.New("no server name to verify the certificate against")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       state.ServerName,
+	})
+
+	return err
+}
+
+// TLSChecker is the implementation of the UptimeChecker interface for TLS hosts (tls://host:port).
+// It performs a TLS handshake and validates the certificate presented by the remote host.
+type TLSChecker struct {
+	logger       *logrus.Entry
+	address      string
+	serverName   string
+	timeout      time.Duration
+	certificates *certificateMetrics
+}
+
+// NewTLSChecker creates a new TLSChecker instance.
+func NewTLSChecker(host Host, registerer prometheus.Registerer) (*TLSChecker, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"component": "tls-checker",
+	})
+
+	u, err := url.Parse(host.Host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("tls host [%s] must be in the form tls://host:port", host.Host)
+	}
+
+	roots, err := loadCertPool(host.TLS.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate authorities for [%s]: %w", host.Host, err)
+	}
+
+	return &TLSChecker{
+		logger:       logger,
+		address:      u.Host,
+		serverName:   u.Hostname(),
+		timeout:      time.Duration(host.Timeout) * time.Second,
+		certificates: newCertificateMetrics(host.TLS, roots, registerer),
+	}, nil
+}
+
+// Check performs a TLS handshake with the remote host. The reported latency includes the connect time.
+func (c *TLSChecker) Check(ctx context.Context) CheckResult {
+	start := time.Now()
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: c.timeout},
+		Config: &tls.Config{
+			ServerName: c.serverName,
+			// the certificate is verified by certificateMetrics, to report on it even when it's invalid
+			InsecureSkipVerify: true,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	latency := time.Since(start)
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if err := c.certificates.observe(&state, c.serverName); err != nil {
+		return CheckResult{Reason: reasonTLSError, Err: err}
+	}
+
+	c.logger.Debugf("Completed TLS handshake with [%s] in [%s]. Counting as up.", c.address, latency)
+	return CheckResult{
+		Up:      true,
+		Latency: latency,
+	}
+}
+
+// boolToFloat converts a boolean to a float, to be used as a gauge value.
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,218 @@
+package internal
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupTLSServer starts an HTTPS server and writes its certificate to a PEM file, to be trusted by the checkers.
+func setupTLSServer(t *testing.T) (*httptest.Server, string) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, content, 0o600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	return server, caFile
+}
+
+// setupExpiredTLSServer starts an HTTPS server with a self-signed certificate which expired yesterday, and
+// writes the certificate to a PEM file, to be trusted by the checkers.
+func setupExpiredTLSServer(t *testing.T) (*httptest.Server, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "expired"},
+		NotBefore:             time.Now().Add(-48 * time.Hour),
+		NotAfter:              time.Now().Add(-24 * time.Hour),
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{certificate}, PrivateKey: key}}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})
+	assert.NoError(t, os.WriteFile(caFile, content, 0o600))
+
+	return server, caFile
+}
+
+func TestTLSCheckerWithTrustedCertificateExpectUp(t *testing.T) {
+	server, caFile := setupTLSServer(t)
+
+	checker, err := NewTLSChecker(Host{
+		Host:    "tls://" + server.Listener.Addr().String(),
+		Timeout: 1,
+		TLS:     TLSOptions{CAFile: caFile},
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.certificates.chainValid))
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.certificates.sanMatch))
+	assert.Equal(t, float64(0), testutil.ToFloat64(checker.certificates.expiring))
+	assert.Greater(t, testutil.ToFloat64(checker.certificates.expiry), float64(0))
+}
+
+func TestTLSCheckerWithUntrustedCertificateExpectDown(t *testing.T) {
+	server, _ := setupTLSServer(t)
+
+	checker, err := NewTLSChecker(Host{
+		Host:    "tls://" + server.Listener.Addr().String(),
+		Timeout: 1,
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Equal(t, float64(0), testutil.ToFloat64(checker.certificates.chainValid))
+	assert.Greater(t, testutil.ToFloat64(checker.certificates.expiry), float64(0))
+}
+
+func TestTLSCheckerWithMismatchingNameExpectDown(t *testing.T) {
+	server, caFile := setupTLSServer(t)
+	port := server.Listener.Addr().String()[strings.LastIndex(server.Listener.Addr().String(), ":")+1:]
+
+	// the test certificate is only valid for example.com and the loopback addresses
+	checker, err := NewTLSChecker(Host{
+		Host:    "tls://localhost:" + port,
+		Timeout: 1,
+		TLS:     TLSOptions{CAFile: caFile},
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Equal(t, float64(0), testutil.ToFloat64(checker.certificates.sanMatch))
+}
+
+func TestTLSCheckerWithinWarningPeriodExpectExpiring(t *testing.T) {
+	server, caFile := setupTLSServer(t)
+
+	// the test certificate is valid for a few decades
+	checker, err := NewTLSChecker(Host{
+		Host:    "tls://" + server.Listener.Addr().String(),
+		Timeout: 1,
+		TLS:     TLSOptions{CAFile: caFile, WarnDays: 100 * 365},
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.certificates.expiring))
+}
+
+func TestHTTPCheckerWithHTTPSHostExpectCertificateMetrics(t *testing.T) {
+	server, caFile := setupTLSServer(t)
+
+	checker, err := NewHTTPChecker(Host{
+		Host:    server.URL,
+		Timeout: 1,
+		TLS:     TLSOptions{CAFile: caFile},
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.True(t, result.Up)
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.certificates.chainValid))
+	assert.Greater(t, testutil.ToFloat64(checker.certificates.notAfter), float64(0))
+}
+
+func TestHTTPCheckerWithUntrustedCertificateExpectCertificateMetrics(t *testing.T) {
+	server, _ := setupTLSServer(t)
+
+	checker, err := NewHTTPChecker(Host{Host: server.URL, Timeout: 1}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Equal(t, reasonTLSError, classifyError(result.Err))
+	assert.Equal(t, float64(0), testutil.ToFloat64(checker.certificates.chainValid))
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.certificates.sanMatch))
+	assert.Greater(t, testutil.ToFloat64(checker.certificates.expiry), float64(0))
+}
+
+func TestHTTPCheckerWithExpiredCertificateExpectCertificateMetrics(t *testing.T) {
+	server, caFile := setupExpiredTLSServer(t)
+
+	checker, err := NewHTTPChecker(Host{
+		Host:    server.URL,
+		Timeout: 1,
+		TLS:     TLSOptions{CAFile: caFile},
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Equal(t, reasonTLSError, classifyError(result.Err))
+	assert.ErrorContains(t, result.Err, "expired")
+	assert.Less(t, testutil.ToFloat64(checker.certificates.expiry), float64(0))
+	assert.Equal(t, float64(0), testutil.ToFloat64(checker.certificates.chainValid))
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.certificates.expiring))
+}
+
+func TestHTTPCheckerWithRedirectToInvalidCertificateExpectDown(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the test certificate is not valid for localhost
+		port := server.Listener.Addr().(*net.TCPAddr).Port
+		http.Redirect(w, r, "https://localhost:"+strconv.Itoa(port)+"/ok", http.StatusFound)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(caFile, content, 0o600))
+
+	checker, err := NewHTTPChecker(Host{
+		Host:    server.URL,
+		Timeout: 1,
+		TLS:     TLSOptions{CAFile: caFile},
+	}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	result := checker.Check(context.Background())
+	assert.False(t, result.Up)
+	assert.Equal(t, reasonTLSError, classifyError(result.Err))
+	assert.ErrorContains(t, result.Err, "not localhost")
+	// the certificate of the host itself is valid
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.certificates.sanMatch))
+	assert.Equal(t, float64(1), testutil.ToFloat64(checker.certificates.chainValid))
+}
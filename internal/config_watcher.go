@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+// configWatchDelay is the delay without changes after which a change of the configuration file is reported,
+// as saving a file usually emits several events.
+const configWatchDelay = 500 * time.Millisecond
+
+// watchConfigFile calls onChange whenever the configuration file changes, until the context is done.
+// The directory of the file is watched rather than the file itself, so that the file keeps being watched
+// when it is replaced, e.g. by editors or by Kubernetes swapping the symlink of a ConfigMap.
+func watchConfigFile(ctx context.Context, logger *log.Entry, path string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	path = filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		target, _ := filepath.EvalSymlinks(path)
+		var timer *time.Timer
+		for {
+			select {
+			case <-ctx.Done():
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				// the file changed, or the symlink it resolves through now points to another file
+				current, _ := filepath.EvalSymlinks(path)
+				if filepath.Clean(event.Name) != path && current == target {
+					continue
+				}
+				if event.Op == fsnotify.Chmod && current == target {
+					continue
+				}
+				target = current
+
+				logger.Debugf("Configuration file [%s] changed: %s", path, event.Op)
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(configWatchDelay, onChange)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.WithError(err).Warnf("Failed to watch the configuration file [%s].", path)
+			}
+		}
+	}()
+
+	return nil
+}
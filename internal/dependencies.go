@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateDependencies checks that the hosts only depend on known hosts, and that the dependencies
+// don't form a cycle.
+func validateDependencies(hosts []Host) error {
+	dependencies := make(map[string][]string, len(hosts))
+	for _, host := range hosts {
+		dependencies[host.Name] = host.DependsOn
+	}
+
+	for _, host := range hosts {
+		for _, dependency := range host.DependsOn {
+			if _, ok := dependencies[dependency]; !ok {
+				return fmt.Errorf("host [%s] depends on unknown host [%s]", host.Name, dependency)
+			}
+		}
+	}
+
+	// depth-first search, a host being visited again while on the current path closes a cycle
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	status := make(map[string]int, len(hosts))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch status[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s -> %s", strings.Join(path, " -> "), name)
+		case visited:
+			return nil
+		}
+
+		status[name] = visiting
+		path = append(path, name)
+		for _, dependency := range dependencies[name] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		status[name] = visited
+
+		return nil
+	}
+
+	for _, host := range hosts {
+		if err := visit(host.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDependenciesWithValidGraphExpectNoError(t *testing.T) {
+	err := validateDependencies([]Host{
+		{Name: "gateway"},
+		{Name: "auth-api", DependsOn: []string{"gateway"}},
+		{Name: "orders", DependsOn: []string{"gateway", "auth-api"}},
+	})
+	assert.NoError(t, err)
+}
+
+func TestValidateDependenciesWithUnknownHostExpectError(t *testing.T) {
+	err := validateDependencies([]Host{
+		{Name: "orders", DependsOn: []string{"gateway"}},
+	})
+	assert.ErrorContains(t, err, "unknown host [gateway]")
+}
+
+func TestValidateDependenciesWithCycleExpectError(t *testing.T) {
+	err := validateDependencies([]Host{
+		{Name: "gateway", DependsOn: []string{"orders"}},
+		{Name: "auth-api", DependsOn: []string{"gateway"}},
+		{Name: "orders", DependsOn: []string{"auth-api"}},
+	})
+	assert.ErrorContains(t, err, "dependency cycle")
+}
+
+func TestStateStoreDownWithUncheckedHostExpectConsideredUp(t *testing.T) {
+	states := NewStateStore()
+	states.Set("gateway", HostState{Up: false})
+	states.Set("auth-api", HostState{Up: true})
+
+	assert.Equal(t, []string{"gateway"}, states.Down([]string{"gateway", "auth-api", "orders"}))
+}
@@ -1,51 +1,81 @@
 package internal
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
-	"os"
-	"os/signal"
 	"strconv"
-	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
-func Serve(port int, registry *prometheus.Registry) {
+// serverShutdownTimeout is the time given to the ongoing requests to complete when the server shuts down.
+const serverShutdownTimeout = 5 * time.Second
+
+// Route is an HTTP handler served along with the metrics, on a pattern of the http.ServeMux syntax.
+type Route struct {
+	Pattern string
+	Handler http.Handler
+}
+
+// Serve serves the metrics of the registry and the routes on the port, until the context is done. The
+// server is then shut down gracefully, letting the ongoing requests complete.
+func Serve(ctx context.Context, port int, registry *prometheus.Registry, routes ...Route) error {
 	logger := log.WithFields(log.Fields{
 		"package": "http",
 	})
 
-	httpServer := &http.Server{
-		Addr: ":" + strconv.Itoa(port),
-	}
-
-	http.Handle(
+	mux := http.NewServeMux()
+	mux.Handle(
 		"/metrics",
 		promhttp.HandlerFor(registry, promhttp.HandlerOpts{
 			EnableOpenMetrics: true,
 		}),
 	)
 
-	hookSignal(logger, httpServer)
-	err := httpServer.ListenAndServe()
-	if err != nil {
-		logger.Fatalf("Failed to start the HTTP server: %v", err)
+	for _, route := range routes {
+		mux.Handle(route.Pattern, route.Handler)
+	}
+
+	httpServer := &http.Server{
+		Addr:    ":" + strconv.Itoa(port),
+		Handler: mux,
 	}
-}
 
-func hookSignal(logger *log.Entry, httpServer *http.Server) {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	served := make(chan error, 1)
 	go func() {
-		<-c
-		logger.Info("Received signal. Shutting down the HTTP server.")
-		err := httpServer.Shutdown(nil)
-		if err != nil {
-			logger.Errorf("Failed to shutdown the HTTP server: %v", err)
-		}
-
-		os.Exit(0)
+		served <- httpServer.ListenAndServe()
 	}()
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("failed to start the HTTP server: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down the HTTP server.")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down the HTTP server: %w", err)
+	}
+
+	return nil
+}
+
+// writeJSON writes a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes a JSON error response with the given status code.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
 }
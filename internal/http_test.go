@@ -1,16 +1,31 @@
 package internal
 
 import (
+	"context"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"net"
 	"net/http"
 	"os"
 	"testing"
+	"time"
 )
 
 func setupHttpTest() {
 	registry := prometheus.NewRegistry()
-	go Serve(8080, registry)
+	go func() {
+		_ = Serve(context.Background(), 8080, registry)
+	}()
+
+	// wait for the server to accept connections
+	for i := 0; i < 50; i++ {
+		conn, err := net.Dial("tcp", "localhost:8080")
+		if err == nil {
+			_ = conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
 }
 
 func TestMain(m *testing.M) {
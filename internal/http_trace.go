@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Phases of an HTTP request measured by httpTimings.
+const (
+	phaseDNS       = "dns"
+	phaseConnect   = "connect"
+	phaseTLS       = "tls"
+	phaseFirstByte = "first_byte"
+	phaseTransfer  = "transfer"
+)
+
+// httpPhases lists the phases of an HTTP request, in the order they happen.
+var httpPhases = []string{phaseDNS, phaseConnect, phaseTLS, phaseFirstByte, phaseTransfer}
+
+// httpTimings measures the duration of each phase of an HTTP request using httptrace.
+// When redirects are followed, the durations of every request are added up.
+type httpTimings struct {
+	mu           sync.Mutex
+	dnsStart     time.Time
+	connectStart map[string]time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	durations    map[string]time.Duration
+}
+
+// newHTTPTimings creates a new httpTimings instance.
+func newHTTPTimings() *httpTimings {
+	return &httpTimings{
+		connectStart: map[string]time.Time{},
+		durations:    map[string]time.Duration{},
+	}
+}
+
+// clientTrace returns the hooks recording the timings, to be attached to the request context.
+func (t *httpTimings) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.record(phaseDNS, &t.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.connectStart[network+addr] = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			// several connections may be attempted in parallel, only the successful one is kept
+			start, ok := t.connectStart[network+addr]
+			delete(t.connectStart, network+addr)
+			if ok && err == nil {
+				t.durations[phaseConnect] += time.Since(start)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.record(phaseTLS, &t.tlsStart)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.wroteRequest = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+			if !t.wroteRequest.IsZero() {
+				t.durations[phaseFirstByte] += t.firstByte.Sub(t.wroteRequest)
+				t.wroteRequest = time.Time{}
+			}
+		},
+	}
+}
+
+// record adds the time elapsed since the start of a phase, and clears the start.
+func (t *httpTimings) record(phase string, start *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !start.IsZero() {
+		t.durations[phase] += time.Since(*start)
+		*start = time.Time{}
+	}
+}
+
+// bodyRead marks the end of the content transfer of the last response.
+func (t *httpTimings) bodyRead() {
+	t.record(phaseTransfer, &t.firstByte)
+}
+
+// get returns the duration of a phase.
+func (t *httpTimings) get(phase string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.durations[phase]
+}
@@ -1,19 +1,51 @@
 package internal
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"github.com/urfave/cli/v2"
 	"net/url"
+	"os/signal"
+	"syscall"
 )
 
 type Host struct {
-	Host     string
-	Timeout  int
-	Interval int
-	Headers  map[string]string
+	// Name identifies the host: its key in the configuration file, or its URL.
+	Name     string   `json:"name"`
+	Host     string   `json:"host"`
+	Tags     []string `json:"tags,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Timeout  int      `json:"timeout"`
+	Interval int      `json:"interval"`
+	// Retries is the number of retries within a check before counting it as failed.
+	Retries int `json:"retries,omitempty"`
+	// RetryDelay is the delay between the attempts of a check, in milliseconds. Defaults to 500.
+	RetryDelay int `json:"retry_delay_ms,omitempty"`
+	// FailThreshold and RecoverThreshold are the numbers of consecutive failed, or successful, checks
+	// required to change the state of the host. Both default to 1.
+	FailThreshold    int               `json:"fail_threshold,omitempty"`
+	RecoverThreshold int               `json:"recover_threshold,omitempty"`
+	Flapping         FlappingOptions   `json:"flapping"`
+	Headers          map[string]string `json:"headers,omitempty"`
+	HTTP             HTTPOptions       `json:"http"`
+	TCP              TCPOptions        `json:"tcp"`
+	DNS              DNSOptions        `json:"dns"`
+	TLS              TLSOptions        `json:"tls"`
+	ICMP             ICMPOptions       `json:"icmp"`
+	GRPC             GRPCOptions       `json:"grpc"`
+	Assertions       AssertionOptions  `json:"assertions"`
+	Histogram        HistogramOptions  `json:"-"`
+	// Notifiers are the names of the notifiers receiving the events of the host. All notifiers when empty.
+	Notifiers []string `json:"notifiers,omitempty"`
+	// DependsOn are the names of the hosts this host depends on. Its failures are not notified while
+	// any of them is down.
+	DependsOn  []string          `json:"depends_on,omitempty"`
+	Escalation EscalationOptions `json:"escalation"`
 }
 
 // readConfiguration reads the configuration from a file.
@@ -55,11 +87,14 @@ func Application(ctx *cli.Context) error {
 		logger.WithError(err).Warn("Failed to read configuration.")
 	}
 
-	envHosts := parseHostsFromEnvVar(logger, ctx)
-	configHosts := parseHostsFromCongFile(logger, ctx)
-	hosts := mergeHosts(envHosts, configHosts)
+	config, err := parseConfig(logger, ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to parse the configuration.")
+		return err
+	}
 
-	if len(hosts) == 0 && len(configHosts) == 0 {
+	// without hosts, the probe modules let Prometheus drive the checks, and the monitors API can add hosts
+	if len(config.Hosts) == 0 && len(config.Modules) == 0 && ctx.String("api-token") == "" {
 		logger.Warn("No hosts to check. Exiting.")
 		return nil
 	}
@@ -69,30 +104,90 @@ func Application(ctx *cli.Context) error {
 		collectors.NewGoCollector(),
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 	)
-	for _, host := range hosts {
-		seeker, err := NewSeeker(
-			host,
-			prometheus.WrapRegistererWith(
-				prometheus.Labels{"host": host.Host},
-				registry,
-			),
-		)
-		if err != nil {
-			logger.WithError(err).Error("Failed to create seeker.")
-			return nil
+
+	prober := NewProber(nil, Host{
+		Timeout: ctx.Int("timeout"),
+		Headers: map[string]string{
+			"User-Agent": ctx.App.Name + "/" + ctx.App.Version,
+		},
+	})
+
+	var options []SupervisorOption
+	if token := ctx.String("api-token"); token != "" {
+		options = append(options, WithMonitorsAPI(token, Host{
+			Timeout:          ctx.Int("timeout"),
+			Interval:         ctx.Int("interval"),
+			Retries:          ctx.Int("retries"),
+			RetryDelay:       ctx.Int("retry-delay-ms"),
+			FailThreshold:    ctx.Int("fail-threshold"),
+			RecoverThreshold: ctx.Int("recover-threshold"),
+			Headers: map[string]string{
+				"User-Agent": ctx.App.Name + "/" + ctx.App.Version,
+			},
+			Histogram: parseHistogramOptions(ctx),
+		}, ctx.String("monitors-file")))
+	}
+
+	supervisor, err := NewSupervisor(registry, prober, func() (Config, error) {
+		viper.Reset()
+		if err := readConfiguration(logger); err != nil {
+			return Config{}, err
 		}
 
-		go seeker.CheckUptime()
+		return parseConfig(logger, ctx)
+	}, options...)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create the supervisor.")
+		return nil
+	}
 
-		logger.Infof("Started checking [%s]", host.Host)
+	if err := supervisor.loadMonitors(); err != nil {
+		logger.WithError(err).Error("Failed to load the monitors.")
+		return nil
 	}
 
-	// start the metrics server
-	Serve(ctx.Int("port"), registry)
+	if err := supervisor.Apply(config); err != nil {
+		logger.WithError(err).Error("Invalid configuration.")
+		return nil
+	}
 
+	// run until SIGINT or SIGTERM
+	runCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	supervisor.hookReload(runCtx)
+	if ctx.Bool("watch-config") {
+		if path := viper.ConfigFileUsed(); path == "" {
+			logger.Warn("No configuration file to watch.")
+		} else if err := watchConfigFile(runCtx, logger, path, func() { _ = supervisor.Reload() }); err != nil {
+			logger.WithError(err).Errorf("Failed to watch the configuration file [%s].", path)
+		}
+	}
+
+	if err := supervisor.Run(runCtx, ctx.Int("port"), registry); err != nil {
+		logger.WithError(err).Error("Failed to shut down cleanly.")
+		return err
+	}
+
+	logger.Info("Stopped Uptimer")
 	return nil
 }
 
+// parseConfig parses the configuration of the supervisor from the flags and the configuration file.
+func parseConfig(logger *log.Entry, ctx *cli.Context) (Config, error) {
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		Hosts:       mergeHosts(parseHostsFromEnvVar(logger, ctx), hosts),
+		Notifiers:   parseNotifiersFromConfigFile(logger),
+		Maintenance: parseMaintenanceFromConfigFile(logger),
+		Modules:     parseModulesFromConfigFile(logger, ctx),
+	}, nil
+}
+
 // parseHostsFromEnvVar parses the hosts string and returns a slice of valid hosts.
 func parseHostsFromEnvVar(logger *log.Entry, ctx *cli.Context) []Host {
 	var output []Host
@@ -112,12 +207,18 @@ func parseHostsFromEnvVar(logger *log.Entry, ctx *cli.Context) []Host {
 		}
 
 		output = append(output, Host{
-			Host:     u.String(),
-			Timeout:  ctx.Int("timeout"),
-			Interval: ctx.Int("interval"),
+			Name:             u.String(),
+			Host:             u.String(),
+			Timeout:          ctx.Int("timeout"),
+			Interval:         ctx.Int("interval"),
+			Retries:          ctx.Int("retries"),
+			RetryDelay:       ctx.Int("retry-delay-ms"),
+			FailThreshold:    ctx.Int("fail-threshold"),
+			RecoverThreshold: ctx.Int("recover-threshold"),
 			Headers: map[string]string{
 				"User-Agent": ctx.App.Name + "/" + ctx.App.Version,
 			},
+			Histogram: parseHistogramOptions(ctx),
 		})
 	}
 
@@ -129,8 +230,11 @@ func parseHostsFromEnvVar(logger *log.Entry, ctx *cli.Context) []Host {
 // parseHostsFromCongFile parses the hosts from the configuration file.
 // A host in the configuration file may not have all its fields
 // filled, in which case the environment variable will be used.
-func parseHostsFromCongFile(logger *log.Entry, ctx *cli.Context) []Host {
+// Hosts with an invalid URL are reported in the error, along with
+// the valid hosts.
+func parseHostsFromCongFile(logger *log.Entry, ctx *cli.Context) ([]Host, error) {
 	var output []Host
+	var errs []error
 
 	hosts := viper.GetStringMapStringSlice("hosts")
 	for key := range hosts {
@@ -140,6 +244,10 @@ func parseHostsFromCongFile(logger *log.Entry, ctx *cli.Context) []Host {
 		// set default values
 		viper.SetDefault(prefix+".timeout", ctx.Int("timeout"))
 		viper.SetDefault(prefix+".interval", ctx.Int("interval"))
+		viper.SetDefault(prefix+".retries", ctx.Int("retries"))
+		viper.SetDefault(prefix+".retry_delay_ms", ctx.Int("retry-delay-ms"))
+		viper.SetDefault(prefix+".fail_threshold", ctx.Int("fail-threshold"))
+		viper.SetDefault(prefix+".recover_threshold", ctx.Int("recover-threshold"))
 		viper.SetDefault(prefix+".headers", map[string]string{})
 
 		logger.Debugf("Found potential host [%s] in configuration file", hostname)
@@ -147,6 +255,7 @@ func parseHostsFromCongFile(logger *log.Entry, ctx *cli.Context) []Host {
 		u, err := url.ParseRequestURI(hostname)
 		if err != nil {
 			logger.WithError(err).Errorf("Failed to parse host entry [%s] from configuration file", hostname)
+			errs = append(errs, fmt.Errorf("invalid host [%s] for [%s]: %w", hostname, key, err))
 			continue
 		}
 
@@ -156,19 +265,207 @@ func parseHostsFromCongFile(logger *log.Entry, ctx *cli.Context) []Host {
 			headers["User-Agent"] = ctx.App.Name + "/" + ctx.App.Version
 		}
 
+		host := parseCheckFromConfigFile(prefix)
 		output = append(output, Host{
-			Host:     u.String(),
-			Timeout:  viper.GetInt(prefix + ".timeout"),
-			Interval: viper.GetInt(prefix + ".interval"),
-			Headers:  headers,
+			Name:             key,
+			Host:             u.String(),
+			Tags:             viper.GetStringSlice(prefix + ".tags"),
+			Type:             host.Type,
+			Timeout:          viper.GetInt(prefix + ".timeout"),
+			Interval:         viper.GetInt(prefix + ".interval"),
+			Retries:          viper.GetInt(prefix + ".retries"),
+			RetryDelay:       viper.GetInt(prefix + ".retry_delay_ms"),
+			FailThreshold:    viper.GetInt(prefix + ".fail_threshold"),
+			RecoverThreshold: viper.GetInt(prefix + ".recover_threshold"),
+			Headers:          headers,
+			HTTP:             host.HTTP,
+			TCP:              host.TCP,
+			DNS:              host.DNS,
+			TLS:              host.TLS,
+			ICMP:             host.ICMP,
+			GRPC:             host.GRPC,
+			Assertions:       host.Assertions,
+			Histogram:        parseHistogramOptions(ctx),
+			Notifiers:        viper.GetStringSlice(prefix + ".notifiers"),
+			DependsOn:        viper.GetStringSlice(prefix + ".depends_on"),
+			Escalation: EscalationOptions{
+				ReminderInterval: viper.GetInt(prefix + ".escalation.reminder_interval"),
+				EscalateAfter:    viper.GetInt(prefix + ".escalation.escalate_after"),
+				EscalateTo:       viper.GetStringSlice(prefix + ".escalation.escalate_to"),
+			},
+			Flapping: FlappingOptions{
+				Threshold: viper.GetInt(prefix + ".flapping.threshold"),
+				Window:    viper.GetInt(prefix + ".flapping.window"),
+			},
 		})
 	}
 
 	log.Infof("Parsed [%d] hosts from the configuration file", len(output))
 
+	return output, errors.Join(errs...)
+}
+
+// parseCheckFromConfigFile parses the options of a check, shared by the hosts and the probe modules,
+// under the given prefix of the configuration file.
+func parseCheckFromConfigFile(prefix string) Host {
+	return Host{
+		Type: viper.GetString(prefix + ".type"),
+		HTTP: HTTPOptions{
+			Method:       viper.GetString(prefix + ".http.method"),
+			Body:         viper.GetString(prefix + ".http.body"),
+			BodyFile:     viper.GetString(prefix + ".http.body_file"),
+			ContentType:  viper.GetString(prefix + ".http.content_type"),
+			StatusCodes:  viper.GetStringSlice(prefix + ".http.status_codes"),
+			Redirects:    viper.GetString(prefix + ".http.redirects"),
+			MaxRedirects: viper.GetInt(prefix + ".http.max_redirects"),
+			FinalURL:     viper.GetString(prefix + ".http.final_url"),
+		},
+		TCP: TCPOptions{
+			Send:   viper.GetString(prefix + ".tcp.send"),
+			Expect: viper.GetString(prefix + ".tcp.expect"),
+		},
+		DNS: DNSOptions{
+			Resolver:      viper.GetString(prefix + ".dns.resolver"),
+			Record:        viper.GetString(prefix + ".dns.record"),
+			Expected:      viper.GetStringSlice(prefix + ".dns.expected"),
+			ExpectedRegex: viper.GetString(prefix + ".dns.expected_regex"),
+		},
+		TLS: TLSOptions{
+			WarnDays: viper.GetInt(prefix + ".tls.warn_days"),
+			CAFile:   viper.GetString(prefix + ".tls.ca_file"),
+		},
+		ICMP: ICMPOptions{
+			Count: viper.GetInt(prefix + ".icmp.count"),
+		},
+		GRPC: GRPCOptions{
+			TLS: viper.GetBool(prefix + ".grpc.tls"),
+		},
+		Assertions: AssertionOptions{
+			Contains:    viper.GetStringSlice(prefix + ".assertions.contains"),
+			NotContains: viper.GetStringSlice(prefix + ".assertions.not_contains"),
+			Regex:       viper.GetStringSlice(prefix + ".assertions.regex"),
+			JSON:        viper.GetStringSlice(prefix + ".assertions.json"),
+		},
+	}
+}
+
+// parseModulesFromConfigFile parses the probe modules from the configuration file. A module is a check
+// without a host, which is given by the target of each probe.
+func parseModulesFromConfigFile(logger *log.Entry, ctx *cli.Context) []Host {
+	var output []Host
+
+	modules := viper.GetStringMap("modules")
+	for name := range modules {
+		prefix := "modules." + name
+
+		viper.SetDefault(prefix+".timeout", ctx.Int("timeout"))
+		viper.SetDefault(prefix+".headers", map[string]string{})
+
+		logger.Debugf("Found probe module [%s] in configuration file", name)
+
+		headers := viper.GetStringMapString(prefix + ".headers")
+		if _, ok := headers["User-Agent"]; !ok {
+			headers["User-Agent"] = ctx.App.Name + "/" + ctx.App.Version
+		}
+
+		module := parseCheckFromConfigFile(prefix)
+		module.Name = name
+		module.Timeout = viper.GetInt(prefix + ".timeout")
+		module.Headers = headers
+		output = append(output, module)
+	}
+
+	log.Infof("Parsed [%d] probe modules from the configuration file", len(output))
+
 	return output
 }
 
+// parseNotifiersFromConfigFile parses the notifiers from the configuration file.
+func parseNotifiersFromConfigFile(logger *log.Entry) []NotifierConfig {
+	var output []NotifierConfig
+
+	notifiers := viper.GetStringMap("notifiers")
+	for name := range notifiers {
+		prefix := "notifiers." + name
+
+		logger.Debugf("Found notifier [%s] in configuration file", name)
+
+		output = append(output, NotifierConfig{
+			Name:    name,
+			Type:    viper.GetString(prefix + ".type"),
+			URL:     viper.GetString(prefix + ".url"),
+			Headers: viper.GetStringMapString(prefix + ".headers"),
+			Timeout: viper.GetInt(prefix + ".timeout"),
+			Retries: viper.GetInt(prefix + ".retries"),
+			SMTP: SMTPOptions{
+				Host:     viper.GetString(prefix + ".smtp.host"),
+				Port:     viper.GetInt(prefix + ".smtp.port"),
+				Username: viper.GetString(prefix + ".smtp.username"),
+				Password: viper.GetString(prefix + ".smtp.password"),
+				From:     viper.GetString(prefix + ".smtp.from"),
+				To:       viper.GetStringSlice(prefix + ".smtp.to"),
+				TLS:      viper.GetString(prefix + ".smtp.tls"),
+				CAFile:   viper.GetString(prefix + ".smtp.ca_file"),
+				Subject:  viper.GetString(prefix + ".smtp.subject"),
+				Text:     viper.GetString(prefix + ".smtp.text"),
+				HTML:     viper.GetString(prefix + ".smtp.html"),
+			},
+			PagerDuty: PagerDutyOptions{
+				RoutingKey: viper.GetString(prefix + ".pagerduty.routing_key"),
+				Severity:   viper.GetString(prefix + ".pagerduty.severity"),
+			},
+			Opsgenie: OpsgenieOptions{
+				APIKey:   viper.GetString(prefix + ".opsgenie.api_key"),
+				Priority: viper.GetString(prefix + ".opsgenie.priority"),
+			},
+			Alertmanager: AlertmanagerOptions{
+				AlertName:      viper.GetString(prefix + ".alertmanager.alert_name"),
+				Labels:         viper.GetStringMapString(prefix + ".alertmanager.labels"),
+				ResendInterval: viper.GetInt(prefix + ".alertmanager.resend_interval"),
+			},
+		})
+	}
+
+	log.Infof("Parsed [%d] notifiers from the configuration file", len(output))
+
+	return output
+}
+
+// parseMaintenanceFromConfigFile parses the scheduled maintenance windows from the configuration file.
+func parseMaintenanceFromConfigFile(logger *log.Entry) []MaintenanceWindow {
+	var output []MaintenanceWindow
+
+	windows := viper.GetStringMap("maintenance")
+	for name := range windows {
+		prefix := "maintenance." + name
+
+		logger.Debugf("Found maintenance window [%s] in configuration file", name)
+
+		output = append(output, MaintenanceWindow{
+			Name:     name,
+			Hosts:    viper.GetStringSlice(prefix + ".hosts"),
+			Tags:     viper.GetStringSlice(prefix + ".tags"),
+			Start:    viper.GetString(prefix + ".start"),
+			End:      viper.GetString(prefix + ".end"),
+			Schedule: viper.GetString(prefix + ".schedule"),
+			Duration: viper.GetInt(prefix + ".duration"),
+			Timezone: viper.GetString(prefix + ".timezone"),
+		})
+	}
+
+	log.Infof("Parsed [%d] maintenance windows from the configuration file", len(output))
+
+	return output
+}
+
+// parseHistogramOptions parses the latency histogram options, shared by all hosts, from the flags.
+func parseHistogramOptions(ctx *cli.Context) HistogramOptions {
+	return HistogramOptions{
+		Buckets: ctx.Float64Slice("latency-buckets"),
+		Native:  ctx.Bool("native-histograms"),
+	}
+}
+
 // mergeHosts merges the hosts from the environment variables and the configuration file.
 // It will keep the configuration file hosts in priority.
 func mergeHosts(envHosts, configHosts []Host) []Host {
@@ -65,7 +65,8 @@ func TestParseHostsFromConfigWithEmptyList(t *testing.T) {
 
 	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
 
-	hosts := parseHostsFromCongFile(logger, ctx)
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
 	assert.Empty(t, hosts)
 }
 
@@ -80,9 +81,11 @@ func TestParseHostsFromConfigWithSingleHostWithParameters(t *testing.T) {
 		Version: "1.0.0",
 	}, flagSet, nil)
 
-	hosts := parseHostsFromCongFile(logger, ctx)
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
 	assert.Len(t, hosts, 1)
 	assert.Equal(t, hosts[0], Host{
+		Name:     "host1",
 		Host:     "http://example.com",
 		Timeout:  10,
 		Interval: 10,
@@ -98,9 +101,11 @@ func TestParseHostsFromConfigWithSingleHostWithoutParameters(t *testing.T) {
 
 	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
 
-	hosts := parseHostsFromCongFile(logger, ctx)
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
 	assert.Len(t, hosts, 1)
 	assert.Equal(t, hosts[0], Host{
+		Name:     "host1",
 		Host:     "http://example.com",
 		Timeout:  5,
 		Interval: 5,
@@ -119,10 +124,12 @@ func TestParseHostsFromConfigWithMultipleHosts(t *testing.T) {
 
 	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
 
-	hosts := parseHostsFromCongFile(logger, ctx)
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
 	assert.Len(t, hosts, 2)
 	// the array is not ordered
 	assert.Contains(t, hosts, Host{
+		Name:     "host1",
 		Host:     "http://example.com",
 		Timeout:  10,
 		Interval: 10,
@@ -131,6 +138,7 @@ func TestParseHostsFromConfigWithMultipleHosts(t *testing.T) {
 		},
 	})
 	assert.Contains(t, hosts, Host{
+		Name:     "host2",
 		Host:     "http://example.org",
 		Timeout:  5,
 		Interval: 5,
@@ -146,10 +154,22 @@ func TestParseHostsFromConfigWithInvalidHost(t *testing.T) {
 
 	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
 
-	hosts := parseHostsFromCongFile(logger, ctx)
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.ErrorContains(t, err, "invalid host [example.com] for [host1]")
 	assert.Empty(t, hosts)
 }
 
+func TestParseConfigWithInvalidHostExpectError(t *testing.T) {
+	setupMainTest()
+	viper.Set("hosts.host1.host", "http://example.com")
+	viper.Set("hosts.host2.host", "not a url")
+
+	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
+
+	_, err := parseConfig(logger, ctx)
+	assert.ErrorContains(t, err, "[host2]")
+}
+
 func TestParseHostsFromConfigOverridesUserAgent(t *testing.T) {
 	setupMainTest()
 	viper.Set("hosts.host1.host", "http://example.com")
@@ -159,9 +179,11 @@ func TestParseHostsFromConfigOverridesUserAgent(t *testing.T) {
 
 	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
 
-	hosts := parseHostsFromCongFile(logger, ctx)
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
 	assert.Len(t, hosts, 1)
 	assert.Equal(t, hosts[0], Host{
+		Name:     "host1",
 		Host:     "http://example.com",
 		Timeout:  5,
 		Interval: 5,
@@ -170,3 +192,159 @@ func TestParseHostsFromConfigOverridesUserAgent(t *testing.T) {
 		},
 	})
 }
+
+func TestParseHostsFromConfigWithTCPHost(t *testing.T) {
+	setupMainTest()
+	viper.Set("hosts.db.host", "tcp://localhost:5432")
+	viper.Set("hosts.db.type", "tcp")
+	viper.Set("hosts.db.tcp.send", "PING\r\n")
+	viper.Set("hosts.db.tcp.expect", "^\\+PONG")
+
+	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
+
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 1)
+	assert.Equal(t, "tcp", hosts[0].checkType())
+	assert.Equal(t, TCPOptions{
+		Send:   "PING\r\n",
+		Expect: "^\\+PONG",
+	}, hosts[0].TCP)
+}
+
+func TestParseHostsFromConfigWithHTTPOptions(t *testing.T) {
+	setupMainTest()
+	viper.Set("hosts.login.host", "https://example.com/login")
+	viper.Set("hosts.login.http.method", "POST")
+	viper.Set("hosts.login.http.status_codes", []any{401, "3xx"})
+	viper.Set("hosts.login.http.redirects", "none")
+
+	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
+
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 1)
+	assert.Equal(t, HTTPOptions{
+		Method:      "POST",
+		StatusCodes: []string{"401", "3xx"},
+		Redirects:   "none",
+	}, hosts[0].HTTP)
+}
+
+func TestParseNotifiersFromConfigWithWebhook(t *testing.T) {
+	setupMainTest()
+	viper.Set("notifiers.ops.type", "webhook")
+	viper.Set("notifiers.ops.url", "https://example.com/hook")
+	viper.Set("notifiers.ops.timeout", 3)
+	viper.Set("notifiers.ops.retries", 2)
+	viper.Set("notifiers.ops.headers", map[string]string{"Authorization": "Bearer token"})
+
+	notifiers := parseNotifiersFromConfigFile(logger)
+	assert.Equal(t, []NotifierConfig{{
+		Name:         "ops",
+		Type:         "webhook",
+		URL:          "https://example.com/hook",
+		Headers:      map[string]string{"Authorization": "Bearer token"},
+		Timeout:      3,
+		Retries:      2,
+		Alertmanager: AlertmanagerOptions{Labels: map[string]string{}},
+	}}, notifiers)
+}
+
+func TestParseHostsFromConfigWithNotifiers(t *testing.T) {
+	setupMainTest()
+	viper.Set("hosts.host1.host", "http://example.com")
+	viper.Set("hosts.host1.notifiers", []string{"ops", "slack"})
+
+	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
+
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 1)
+	assert.Equal(t, []string{"ops", "slack"}, hosts[0].Notifiers)
+}
+
+func TestParseHostsFromConfigWithThresholds(t *testing.T) {
+	setupMainTest()
+	viper.Set("hosts.host1.host", "http://example.com")
+	viper.Set("hosts.host1.retries", 2)
+	viper.Set("hosts.host1.retry_delay_ms", 100)
+	viper.Set("hosts.host1.fail_threshold", 3)
+	viper.Set("hosts.host1.recover_threshold", 2)
+
+	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
+
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 1)
+	assert.Equal(t, 2, hosts[0].Retries)
+	assert.Equal(t, 100, hosts[0].RetryDelay)
+	assert.Equal(t, 3, hosts[0].FailThreshold)
+	assert.Equal(t, 2, hosts[0].RecoverThreshold)
+}
+
+func TestParseMaintenanceFromConfigWithRecurringWindow(t *testing.T) {
+	setupMainTest()
+	viper.Set("maintenance.backups.tags", []string{"database"})
+	viper.Set("maintenance.backups.schedule", "0 2 * * SUN")
+	viper.Set("maintenance.backups.duration", 3600)
+	viper.Set("maintenance.backups.timezone", "Europe/Paris")
+
+	windows := parseMaintenanceFromConfigFile(logger)
+	assert.Equal(t, []MaintenanceWindow{{
+		Name:     "backups",
+		Tags:     []string{"database"},
+		Schedule: "0 2 * * SUN",
+		Duration: 3600,
+		Timezone: "Europe/Paris",
+	}}, windows)
+}
+
+func TestParseHostsFromConfigWithDependencies(t *testing.T) {
+	setupMainTest()
+	viper.Set("hosts.orders.host", "http://orders.example.com")
+	viper.Set("hosts.orders.depends_on", []string{"gateway", "auth-api"})
+
+	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
+
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 1)
+	assert.Equal(t, []string{"gateway", "auth-api"}, hosts[0].DependsOn)
+}
+
+func TestParseHostsFromConfigWithEscalation(t *testing.T) {
+	setupMainTest()
+	viper.Set("hosts.host1.host", "http://example.com")
+	viper.Set("hosts.host1.escalation.reminder_interval", 1800)
+	viper.Set("hosts.host1.escalation.escalate_after", 3600)
+	viper.Set("hosts.host1.escalation.escalate_to", []string{"pagerduty"})
+
+	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
+
+	hosts, err := parseHostsFromCongFile(logger, ctx)
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 1)
+	assert.Equal(t, EscalationOptions{
+		ReminderInterval: 1800,
+		EscalateAfter:    3600,
+		EscalateTo:       []string{"pagerduty"},
+	}, hosts[0].Escalation)
+}
+
+func TestParseModulesFromConfigWithHTTPModule(t *testing.T) {
+	setupMainTest()
+	viper.Set("modules.http_post.timeout", 3)
+	viper.Set("modules.http_post.http.method", "POST")
+	viper.Set("modules.http_post.assertions.contains", []string{"ok"})
+
+	ctx := cli.NewContext(&cli.App{}, flagSet, nil)
+
+	modules := parseModulesFromConfigFile(logger, ctx)
+	assert.Len(t, modules, 1)
+	assert.Equal(t, "http_post", modules[0].Name)
+	assert.Equal(t, 3, modules[0].Timeout)
+	assert.Equal(t, "POST", modules[0].HTTP.Method)
+	assert.Equal(t, []string{"ok"}, modules[0].Assertions.Contains)
+	assert.Equal(t, map[string]string{"User-Agent": "/"}, modules[0].Headers)
+}
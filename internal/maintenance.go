@@ -0,0 +1,273 @@
+package internal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// MaintenanceWindow holds a scheduled maintenance window, as defined in the configuration file.
+// A window is either one-off, from Start to End, or recurring, starting on every activation of
+// Schedule and lasting Duration.
+type MaintenanceWindow struct {
+	Name string
+	// Hosts are the names or URLs of the hosts in maintenance.
+	Hosts []string
+	// Tags select the hosts in maintenance by tag.
+	Tags []string
+	// Start and End bound a one-off window, in the RFC 3339 format.
+	Start string
+	End   string
+	// Schedule is a cron expression (minute, hour, day of month, month, day of week) of a recurring window.
+	Schedule string
+	// Duration is the duration of a recurring window, in seconds.
+	Duration int
+	// Timezone is the timezone of the schedule. Defaults to UTC.
+	Timezone string
+}
+
+// hostSelector selects hosts by name, URL or tag.
+type hostSelector struct {
+	hosts []string
+	tags  []string
+}
+
+// matches tells whether the host is selected.
+func (s hostSelector) matches(name, url string, tags []string) bool {
+	if slices.Contains(s.hosts, name) || slices.Contains(s.hosts, url) {
+		return true
+	}
+
+	return slices.ContainsFunc(tags, func(tag string) bool {
+		return slices.Contains(s.tags, tag)
+	})
+}
+
+// maintenanceWindow is a MaintenanceWindow ready to be evaluated.
+type maintenanceWindow struct {
+	name     string
+	selector hostSelector
+	start    time.Time
+	end      time.Time
+	schedule cron.Schedule
+	duration time.Duration
+}
+
+// active tells whether the window is ongoing at the given time.
+func (w maintenanceWindow) active(now time.Time) bool {
+	if w.schedule == nil {
+		return !now.Before(w.start) && now.Before(w.end)
+	}
+
+	// the window is ongoing when it was activated within the last duration
+	return !w.schedule.Next(now.Add(-w.duration)).After(now)
+}
+
+// newMaintenanceWindow validates a maintenance window.
+func newMaintenanceWindow(window MaintenanceWindow) (maintenanceWindow, error) {
+	output := maintenanceWindow{
+		name:     window.Name,
+		selector: hostSelector{hosts: window.Hosts, tags: window.Tags},
+	}
+
+	if len(window.Hosts) == 0 && len(window.Tags) == 0 {
+		return output, fmt.Errorf("maintenance window [%s] must select hosts or tags", window.Name)
+	}
+
+	if window.Schedule != "" {
+		if window.Duration <= 0 {
+			return output, fmt.Errorf("recurring maintenance window [%s] must have a positive duration", window.Name)
+		}
+
+		timezone := orDefault(window.Timezone, "UTC")
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return output, fmt.Errorf("invalid timezone for maintenance window [%s]: %w", window.Name, err)
+		}
+
+		schedule, err := cron.ParseStandard("CRON_TZ=" + timezone + " " + window.Schedule)
+		if err != nil {
+			return output, fmt.Errorf("invalid schedule for maintenance window [%s]: %w", window.Name, err)
+		}
+
+		output.schedule = schedule
+		output.duration = time.Duration(window.Duration) * time.Second
+		return output, nil
+	}
+
+	start, err := time.Parse(time.RFC3339, window.Start)
+	if err != nil {
+		return output, fmt.Errorf("invalid start for maintenance window [%s]: %w", window.Name, err)
+	}
+	end, err := time.Parse(time.RFC3339, window.End)
+	if err != nil {
+		return output, fmt.Errorf("invalid end for maintenance window [%s]: %w", window.Name, err)
+	}
+	if !end.After(start) {
+		return output, fmt.Errorf("maintenance window [%s] must end after it starts", window.Name)
+	}
+
+	output.start = start
+	output.end = end
+	return output, nil
+}
+
+// Silence is an ad-hoc maintenance of some hosts, created through the HTTP API.
+type Silence struct {
+	ID        string    `json:"id"`
+	Hosts     []string  `json:"hosts,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+}
+
+// active tells whether the silence is ongoing at the given time.
+func (s Silence) active(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// Maintenance tells whether hosts are in maintenance, either from the scheduled windows or the silences.
+// Hosts keep being checked during maintenance, but their notifications are suppressed.
+type Maintenance struct {
+	logger   *logrus.Entry
+	mu       sync.RWMutex
+	windows  []maintenanceWindow
+	silences map[string]Silence
+}
+
+// NewMaintenance creates a new Maintenance instance with the scheduled windows.
+func NewMaintenance(windows []MaintenanceWindow) (*Maintenance, error) {
+	maintenance := &Maintenance{
+		logger: logrus.WithFields(logrus.Fields{
+			"component": "maintenance",
+		}),
+		silences: map[string]Silence{},
+	}
+
+	if err := maintenance.SetWindows(windows); err != nil {
+		return nil, err
+	}
+
+	return maintenance, nil
+}
+
+// SetWindows replaces the scheduled windows, keeping the silences. The windows are left untouched
+// when any of them is invalid.
+func (m *Maintenance) SetWindows(windows []MaintenanceWindow) error {
+	compiled, err := newMaintenanceWindows(windows)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.windows = compiled
+	return nil
+}
+
+// newMaintenanceWindows validates the maintenance windows.
+func newMaintenanceWindows(windows []MaintenanceWindow) ([]maintenanceWindow, error) {
+	var output []maintenanceWindow
+	for _, window := range windows {
+		compiled, err := newMaintenanceWindow(window)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, compiled)
+	}
+
+	return output, nil
+}
+
+// Active tells whether the host is in maintenance at the given time.
+func (m *Maintenance) Active(name, url string, tags []string, now time.Time) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, window := range m.windows {
+		if window.selector.matches(name, url, tags) && window.active(now) {
+			return true
+		}
+	}
+
+	for _, silence := range m.silences {
+		selector := hostSelector{hosts: silence.Hosts, tags: silence.Tags}
+		if selector.matches(name, url, tags) && silence.active(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddSilence validates and stores a silence, returning it with its generated identifier.
+func (m *Maintenance) AddSilence(silence Silence) (Silence, error) {
+	if len(silence.Hosts) == 0 && len(silence.Tags) == 0 {
+		return silence, errors.New("silence must select hosts or tags")
+	}
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = time.Now()
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return silence, errors.New("silence must end after it starts")
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return silence, err
+	}
+	silence.ID = hex.EncodeToString(id)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneSilences(time.Now())
+	m.silences[silence.ID] = silence
+	m.logger.Infof("Created silence [%s] for hosts %v and tags %v until [%s].", silence.ID, silence.Hosts, silence.Tags, silence.EndsAt.Format(time.RFC3339))
+
+	return silence, nil
+}
+
+// RemoveSilence removes a silence, telling whether it existed.
+func (m *Maintenance) RemoveSilence(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.silences[id]; !ok {
+		return false
+	}
+
+	delete(m.silences, id)
+	m.logger.Infof("Removed silence [%s].", id)
+	return true
+}
+
+// Silences returns the silences which are not expired yet, ordered by start.
+func (m *Maintenance) Silences() []Silence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneSilences(time.Now())
+	return slices.SortedFunc(maps.Values(m.silences), func(a, b Silence) int {
+		return a.StartsAt.Compare(b.StartsAt)
+	})
+}
+
+// pruneSilences removes the expired silences. The lock must be held.
+func (m *Maintenance) pruneSilences(now time.Time) {
+	for id, silence := range m.silences {
+		if !now.Before(silence.EndsAt) {
+			delete(m.silences, id)
+		}
+	}
+}
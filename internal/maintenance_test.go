@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceActiveWithOneOffWindowExpectActiveWithinBounds(t *testing.T) {
+	maintenance, err := NewMaintenance([]MaintenanceWindow{{
+		Name:  "deploy",
+		Hosts: []string{"api"},
+		Start: "2024-01-01T10:00:00Z",
+		End:   "2024-01-01T11:00:00Z",
+	}})
+	assert.NoError(t, err)
+
+	within := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	assert.True(t, maintenance.Active("api", "http://api.example.com", nil, within))
+	assert.False(t, maintenance.Active("web", "http://web.example.com", nil, within))
+	assert.False(t, maintenance.Active("api", "http://api.example.com", nil, within.Add(time.Hour)))
+}
+
+func TestMaintenanceActiveWithRecurringWindowExpectActiveInTimezone(t *testing.T) {
+	maintenance, err := NewMaintenance([]MaintenanceWindow{{
+		Name:     "backups",
+		Tags:     []string{"database"},
+		Schedule: "0 2 * * SUN",
+		Duration: 3600,
+		Timezone: "Europe/Paris",
+	}})
+	assert.NoError(t, err)
+
+	// 2024-01-07 is a Sunday, Paris is UTC+1 in winter
+	assert.True(t, maintenance.Active("db", "tcp://db:5432", []string{"database"}, time.Date(2024, 1, 7, 1, 30, 0, 0, time.UTC)))
+	assert.False(t, maintenance.Active("db", "tcp://db:5432", []string{"database"}, time.Date(2024, 1, 7, 2, 30, 0, 0, time.UTC)))
+	assert.False(t, maintenance.Active("db", "tcp://db:5432", []string{"database"}, time.Date(2024, 1, 8, 1, 30, 0, 0, time.UTC)))
+	assert.False(t, maintenance.Active("db", "tcp://db:5432", []string{"cache"}, time.Date(2024, 1, 7, 1, 30, 0, 0, time.UTC)))
+}
+
+func TestNewMaintenanceWithInvalidWindowExpectError(t *testing.T) {
+	for name, window := range map[string]MaintenanceWindow{
+		"no selector":      {Name: "test", Start: "2024-01-01T10:00:00Z", End: "2024-01-01T11:00:00Z"},
+		"end before start": {Name: "test", Hosts: []string{"api"}, Start: "2024-01-01T11:00:00Z", End: "2024-01-01T10:00:00Z"},
+		"invalid schedule": {Name: "test", Hosts: []string{"api"}, Schedule: "every sunday", Duration: 60},
+		"no duration":      {Name: "test", Hosts: []string{"api"}, Schedule: "0 2 * * *"},
+		"invalid timezone": {Name: "test", Hosts: []string{"api"}, Schedule: "0 2 * * *", Duration: 60, Timezone: "Mars/Olympus"},
+	} {
+		_, err := NewMaintenance([]MaintenanceWindow{window})
+		assert.Error(t, err, name)
+	}
+}
+
+func TestMaintenanceWithSilenceExpectActiveUntilRemoved(t *testing.T) {
+	maintenance, err := NewMaintenance(nil)
+	assert.NoError(t, err)
+
+	silence, err := maintenance.AddSilence(Silence{Tags: []string{"frontend"}, EndsAt: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, silence.ID)
+
+	assert.True(t, maintenance.Active("web", "http://web.example.com", []string{"frontend"}, time.Now()))
+	assert.Len(t, maintenance.Silences(), 1)
+
+	assert.True(t, maintenance.RemoveSilence(silence.ID))
+	assert.False(t, maintenance.RemoveSilence(silence.ID))
+	assert.False(t, maintenance.Active("web", "http://web.example.com", []string{"frontend"}, time.Now()))
+}
+
+func TestMaintenanceSilencesWithExpiredSilenceExpectPruned(t *testing.T) {
+	maintenance, err := NewMaintenance(nil)
+	assert.NoError(t, err)
+
+	_, err = maintenance.AddSilence(Silence{
+		Hosts:    []string{"api"},
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   time.Now().Add(-time.Minute),
+	})
+	assert.NoError(t, err)
+
+	assert.Empty(t, maintenance.Silences())
+}
+
+func TestMaintenanceSetWindowsWithInvalidWindowExpectPreviousKept(t *testing.T) {
+	window := MaintenanceWindow{Name: "test", Hosts: []string{"api"}, Start: "2024-01-01T10:00:00Z", End: "2024-01-01T11:00:00Z"}
+	maintenance, err := NewMaintenance([]MaintenanceWindow{window})
+	assert.NoError(t, err)
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	assert.Error(t, maintenance.SetWindows([]MaintenanceWindow{{Name: "invalid"}}))
+	assert.True(t, maintenance.Active("api", "", nil, now))
+
+	assert.NoError(t, maintenance.SetWindows(nil))
+	assert.False(t, maintenance.Active("api", "", nil, now))
+}
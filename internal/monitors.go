@@ -0,0 +1,383 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Sources of the monitors.
+const (
+	sourceConfig = "config"
+	sourceAPI    = "api"
+)
+
+// Errors of the monitor operations, mapped to the status codes of the monitors API.
+var (
+	errMonitorNotFound = errors.New("monitor not found")
+	errMonitorExists   = errors.New("monitor already exists")
+	errMonitorReadOnly = errors.New("monitor is defined in the configuration, and can only be paused or resumed")
+	errMonitorPaused   = errors.New("monitor is paused")
+)
+
+// Monitor is a checked host, as exposed by the monitors API.
+type Monitor struct {
+	Host
+	// Source is where the monitor is defined: config for the configuration file and the environment, api
+	// for the monitors created through the API.
+	Source string `json:"source"`
+	Paused bool   `json:"paused"`
+}
+
+// monitorsAPIOptions holds the options of the monitors API.
+type monitorsAPIOptions struct {
+	// token is the bearer token of the monitors and silences APIs, which are disabled without it.
+	token string
+	// defaults are the options of the monitors which are not set on creation.
+	defaults Host
+	// path is the file the monitors created through the API are persisted to. They are not persisted without it.
+	path string
+}
+
+// monitorsFile is the content of the file the monitors are persisted to.
+type monitorsFile struct {
+	Monitors []Host   `json:"monitors"`
+	Paused   []string `json:"paused,omitempty"`
+}
+
+// WithMonitorsAPI enables the monitors and silences APIs, authenticated with the bearer token. The monitors created
+// through the API take the defaults for the options they don't set, and are persisted to the file at path
+// when it is not empty.
+func WithMonitorsAPI(token string, defaults Host, path string) SupervisorOption {
+	return func(s *Supervisor) {
+		s.monitorsAPI = monitorsAPIOptions{
+			token:    token,
+			defaults: defaults,
+			path:     path,
+		}
+	}
+}
+
+// newMonitorHost returns the options of a new monitor, before the options of the request are applied.
+func (s *Supervisor) newMonitorHost() Host {
+	host := s.monitorsAPI.defaults
+	host.Headers = maps.Clone(host.Headers)
+
+	return host
+}
+
+// Monitors returns every monitor, ordered by name.
+func (s *Supervisor) Monitors() []Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var output []Monitor
+	for _, host := range slices.Concat(s.config.Hosts, slices.Collect(maps.Values(s.monitors))) {
+		output = append(output, s.monitor(host))
+	}
+
+	slices.SortFunc(output, func(a, b Monitor) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return output
+}
+
+// Monitor returns the monitor with the given name.
+func (s *Supervisor) Monitor(name string) (Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	host, ok := s.findHost(name)
+	if !ok {
+		return Monitor{}, errMonitorNotFound
+	}
+
+	return s.monitor(host), nil
+}
+
+// AddMonitor validates and starts a new monitor.
+func (s *Supervisor) AddMonitor(host Host) (Monitor, error) {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := validateMonitor(host); err != nil {
+		return Monitor{}, err
+	}
+	if _, ok := s.findHost(host.Name); ok {
+		return Monitor{}, errMonitorExists
+	}
+
+	monitors := maps.Clone(s.monitors)
+	monitors[host.Name] = host
+	if err := s.applyMonitors(monitors, s.paused); err != nil {
+		return Monitor{}, err
+	}
+	s.logger.Infof("Added monitor [%s] for [%s].", host.Name, host.Host)
+
+	return s.monitor(host), nil
+}
+
+// UpdateMonitor replaces a monitor created through the API. The monitor is restarted when it changed.
+func (s *Supervisor) UpdateMonitor(name string, host Host) (Monitor, error) {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkWritable(name); err != nil {
+		return Monitor{}, err
+	}
+	host.Name = name
+	if err := validateMonitor(host); err != nil {
+		return Monitor{}, err
+	}
+
+	monitors := maps.Clone(s.monitors)
+	monitors[name] = host
+	if err := s.applyMonitors(monitors, s.paused); err != nil {
+		return Monitor{}, err
+	}
+	s.logger.Infof("Updated monitor [%s].", name)
+
+	return s.monitor(host), nil
+}
+
+// RemoveMonitor stops and removes a monitor created through the API.
+func (s *Supervisor) RemoveMonitor(name string) error {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkWritable(name); err != nil {
+		return err
+	}
+
+	monitors := maps.Clone(s.monitors)
+	delete(monitors, name)
+	if err := s.applyMonitors(monitors, s.paused); err != nil {
+		return err
+	}
+	s.logger.Infof("Removed monitor [%s].", name)
+
+	return nil
+}
+
+// PauseMonitor stops checking a monitor, or starts checking it again. Monitors defined in the configuration
+// stay paused across reloads.
+func (s *Supervisor) PauseMonitor(name string, paused bool) (Monitor, error) {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	host, ok := s.findHost(name)
+	if !ok {
+		return Monitor{}, errMonitorNotFound
+	}
+
+	pauses := maps.Clone(s.paused)
+	if paused {
+		pauses[name] = true
+	} else {
+		delete(pauses, name)
+	}
+	if err := s.applyMonitors(s.monitors, pauses); err != nil {
+		return Monitor{}, err
+	}
+
+	if paused {
+		s.logger.Infof("Paused monitor [%s].", name)
+	} else {
+		s.logger.Infof("Resumed monitor [%s].", name)
+	}
+
+	return s.monitor(host), nil
+}
+
+// CheckMonitor checks a monitor immediately, and returns the result. The check counts towards the state of
+// the monitor, as the periodic ones.
+func (s *Supervisor) CheckMonitor(ctx context.Context, name string) (CheckResult, error) {
+	s.mu.Lock()
+	host, ok := s.findHost(name)
+	running := s.seekers[host.Host]
+	s.mu.Unlock()
+
+	if !ok {
+		return CheckResult{}, errMonitorNotFound
+	}
+	if running == nil {
+		return CheckResult{}, errMonitorPaused
+	}
+
+	// give up when the seeker is stopped before running the check
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-running.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return running.seeker.Trigger(ctx)
+}
+
+// applyMonitors applies the monitors and the pauses along with the current configuration, then persists
+// them. Both locks must be held.
+func (s *Supervisor) applyMonitors(monitors map[string]Host, paused map[string]bool) error {
+	if err := s.apply(s.config, monitors, paused); err != nil {
+		return err
+	}
+
+	if err := s.saveMonitors(); err != nil {
+		s.logger.WithError(err).Errorf("Failed to persist the monitors to [%s].", s.monitorsAPI.path)
+	}
+
+	return nil
+}
+
+// findHost returns the host of the configuration or the monitor with the given name. The lock must be held.
+func (s *Supervisor) findHost(name string) (Host, bool) {
+	if host, ok := s.monitors[name]; ok {
+		return host, true
+	}
+
+	for _, host := range s.config.Hosts {
+		if host.Name == name {
+			return host, true
+		}
+	}
+
+	return Host{}, false
+}
+
+// checkWritable checks that the monitor exists and was created through the API. The lock must be held.
+func (s *Supervisor) checkWritable(name string) error {
+	if _, ok := s.monitors[name]; ok {
+		return nil
+	}
+	if _, ok := s.findHost(name); ok {
+		return errMonitorReadOnly
+	}
+
+	return errMonitorNotFound
+}
+
+// monitor returns the monitor of a host. The lock must be held.
+func (s *Supervisor) monitor(host Host) Monitor {
+	source := sourceConfig
+	if _, ok := s.monitors[host.Name]; ok {
+		source = sourceAPI
+	}
+
+	return Monitor{
+		Host:   host,
+		Source: source,
+		Paused: s.paused[host.Name],
+	}
+}
+
+// loadMonitors loads the monitors persisted to the file, if any. They are applied along with the next configuration.
+func (s *Supervisor) loadMonitors() error {
+	if s.monitorsAPI.path == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(s.monitorsAPI.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var file monitorsFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("invalid monitors file [%s]: %w", s.monitorsAPI.path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, host := range file.Monitors {
+		host.Histogram = s.monitorsAPI.defaults.Histogram
+		s.monitors[host.Name] = host
+	}
+	for _, name := range file.Paused {
+		s.paused[name] = true
+	}
+	s.logger.Infof("Loaded [%d] monitors from [%s].", len(file.Monitors), s.monitorsAPI.path)
+
+	return nil
+}
+
+// saveMonitors persists the monitors created through the API and the pauses to the file, if any. The file is
+// replaced atomically, so that it is never left half written. The lock must be held.
+func (s *Supervisor) saveMonitors() error {
+	if s.monitorsAPI.path == "" {
+		return nil
+	}
+
+	file := monitorsFile{
+		Monitors: []Host{},
+		Paused:   slices.Sorted(maps.Keys(s.paused)),
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.monitors)) {
+		file.Monitors = append(file.Monitors, s.monitors[name])
+	}
+
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(s.monitorsAPI.path), filepath.Base(s.monitorsAPI.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(content); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), s.monitorsAPI.path)
+}
+
+// validateMonitor checks the options of a monitor which the seekers don't check themselves.
+func validateMonitor(host Host) error {
+	if host.Name == "" {
+		return errors.New("monitor must have a name")
+	}
+	if _, err := url.ParseRequestURI(host.Host); err != nil {
+		return fmt.Errorf("invalid host [%s]: %w", host.Host, err)
+	}
+	if host.Interval <= 0 {
+		return fmt.Errorf("interval of [%s] must be positive, got [%d]", host.Name, host.Interval)
+	}
+	// the files are read by uptimer, so monitors created through the API could read any of its files
+	if host.HTTP.BodyFile != "" {
+		return fmt.Errorf("body_file of [%s] is not allowed for monitors", host.Name)
+	}
+	if host.TLS.CAFile != "" {
+		return fmt.Errorf("ca_file of [%s] is not allowed for monitors", host.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,416 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+// States of a host reported in events.
+const (
+	stateUp   = "up"
+	stateDown = "down"
+)
+
+// Kinds of events.
+const (
+	eventTransition      = "transition"
+	eventFlappingStarted = "flapping_started"
+	eventFlappingStopped = "flapping_stopped"
+	eventReminder        = "reminder"
+	eventEscalation      = "escalation"
+	eventStopped         = "stopped"
+)
+
+// Defaults of the notifiers.
+const (
+	defaultNotifierTimeout = 10
+	defaultRetryDelay      = time.Second
+)
+
+// Event describes a state transition of a host, sent to the notifiers. While a host is flapping, its
+// transitions are replaced by a single event when it starts flapping, and one when it stops.
+type Event struct {
+	Kind      string
+	Host      string
+	Tags      []string
+	OldState  string
+	NewState  string
+	Reason    string
+	Latency   time.Duration
+	Timestamp time.Time
+	// Duration is the duration of the outage, set when the host recovers.
+	Duration time.Duration
+}
+
+// title returns a short, human-readable summary of the event.
+func (e Event) title() string {
+	switch e.Kind {
+	case eventFlappingStarted:
+		return fmt.Sprintf("%s is flapping", e.Host)
+	case eventFlappingStopped:
+		return fmt.Sprintf("%s stopped flapping and is %s", e.Host, e.NewState)
+	case eventReminder:
+		return fmt.Sprintf("%s is still down after %s (%s)", e.Host, e.Duration.Round(time.Second), e.Reason)
+	case eventEscalation:
+		return fmt.Sprintf("%s is still down after %s, escalating (%s)", e.Host, e.Duration.Round(time.Second), e.Reason)
+	case eventStopped:
+		return fmt.Sprintf("%s is no longer checked, resolving its outage after %s", e.Host, e.Duration.Round(time.Second))
+	}
+
+	if e.NewState == stateUp {
+		if e.Duration > 0 {
+			return fmt.Sprintf("%s is back up after %s", e.Host, e.Duration.Round(time.Second))
+		}
+		return fmt.Sprintf("%s is back up", e.Host)
+	}
+
+	return fmt.Sprintf("%s is down (%s)", e.Host, e.Reason)
+}
+
+// eventField is a named value describing an event, rendered by the chat notifiers.
+type eventField struct {
+	Name  string
+	Value string
+}
+
+// fields returns the details of the event, in display order.
+func (e Event) fields() []eventField {
+	fields := []eventField{
+		{Name: "Host", Value: e.Host},
+		{Name: "State", Value: strings.ToUpper(e.OldState) + " → " + strings.ToUpper(e.NewState)},
+	}
+	if e.Reason != "" {
+		fields = append(fields, eventField{Name: "Reason", Value: e.Reason})
+	}
+	fields = append(fields, eventField{Name: "Latency", Value: e.Latency.Round(time.Millisecond).String()})
+	if e.Duration > 0 {
+		fields = append(fields, eventField{Name: "Outage duration", Value: e.Duration.Round(time.Second).String()})
+	}
+
+	return fields
+}
+
+// Notifier is the interface that defines how an event is sent to a notification channel.
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// statefulNotifier is a Notifier keeping a state across events, which is taken over by the notifier replacing
+// it when its configuration changes.
+type statefulNotifier interface {
+	takeOver(previous Notifier)
+}
+
+// NotifierConfig holds the configuration of a notifier, as defined in the configuration file.
+type NotifierConfig struct {
+	Name    string
+	Type    string
+	URL     string
+	Headers map[string]string
+	// Timeout is the timeout of each attempt, in seconds.
+	Timeout int
+	// Retries is the number of attempts made after a failed one.
+	Retries      int
+	SMTP         SMTPOptions
+	PagerDuty    PagerDutyOptions
+	Opsgenie     OpsgenieOptions
+	Alertmanager AlertmanagerOptions
+}
+
+// newNotifier creates the Notifier matching the type of the configuration.
+func newNotifier(config NotifierConfig) (Notifier, error) {
+	switch config.Type {
+	case "webhook":
+		return NewWebhookNotifier(config)
+	case "slack":
+		return NewSlackNotifier(config)
+	case "discord":
+		return NewDiscordNotifier(config)
+	case "teams":
+		return NewTeamsNotifier(config)
+	case "smtp":
+		return NewSMTPNotifier(config)
+	case "pagerduty":
+		return NewPagerDutyNotifier(config)
+	case "opsgenie":
+		return NewOpsgenieNotifier(config)
+	case "alertmanager":
+		return NewAlertmanagerNotifier(config)
+	default:
+		return nil, fmt.Errorf("unsupported notifier type [%s] for [%s]", config.Type, config.Name)
+	}
+}
+
+// registeredNotifier is a notifier along with its delivery options, and the configuration it was created from.
+type registeredNotifier struct {
+	notifier Notifier
+	timeout  time.Duration
+	retries  int
+	config   NotifierConfig
+}
+
+// newRegisteredNotifier wraps a notifier with its delivery options. The timeout applies to each attempt, in seconds.
+func newRegisteredNotifier(notifier Notifier, timeout int, retries int) registeredNotifier {
+	if timeout <= 0 {
+		timeout = defaultNotifierTimeout
+	}
+
+	return registeredNotifier{
+		notifier: notifier,
+		timeout:  time.Duration(timeout) * time.Second,
+		retries:  max(retries, 0),
+	}
+}
+
+// queueKey identifies the queue of the events of a host for a notifier.
+type queueKey struct {
+	notifier string
+	host     string
+}
+
+// eventQueue holds the event of a host waiting for the one being delivered to a notifier. Only the latest
+// event is kept, as it replaces the state reported by the previous ones.
+type eventQueue struct {
+	registered registeredNotifier
+	pending    *Event
+}
+
+// Dispatcher sends events to the notifiers. Events are delivered asynchronously, so that slow
+// notification channels don't delay the checks. The events of a host are delivered to each notifier
+// in order, one at a time.
+type Dispatcher struct {
+	logger        *logrus.Entry
+	mu            sync.RWMutex
+	notifiers     map[string]registeredNotifier
+	queuesMu      sync.Mutex
+	queues        map[queueKey]*eventQueue
+	retryDelay    time.Duration
+	notifications *prometheus.CounterVec
+	wg            sync.WaitGroup
+}
+
+// NewDispatcher creates a new Dispatcher instance with the notifiers of the configurations.
+func NewDispatcher(configs []NotifierConfig, registerer prometheus.Registerer) (*Dispatcher, error) {
+	dispatcher := &Dispatcher{
+		logger: logrus.WithFields(logrus.Fields{
+			"component": "dispatcher",
+		}),
+		notifiers:  map[string]registeredNotifier{},
+		queues:     map[queueKey]*eventQueue{},
+		retryDelay: defaultRetryDelay,
+		notifications: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Name: "uptime_notifications_total",
+			Help: "The number of notifications sent, by notifier and result.",
+		}, []string{"notifier", "result"}),
+	}
+
+	if err := dispatcher.Reconfigure(configs); err != nil {
+		return nil, err
+	}
+
+	return dispatcher, nil
+}
+
+// Register adds a notifier to the dispatcher. The timeout applies to each attempt, in seconds.
+func (d *Dispatcher) Register(name string, notifier Notifier, timeout int, retries int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.notifiers[name] = newRegisteredNotifier(notifier, timeout, retries)
+}
+
+// Reconfigure replaces the notifiers with those of the configurations. The notifiers whose configuration
+// is unchanged are kept, so that they keep their state (e.g. the firing alerts), and the others are closed
+// once their state is taken over by the notifiers replacing them. The notifiers are left untouched when any
+// configuration is invalid.
+func (d *Dispatcher) Reconfigure(configs []NotifierConfig) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	notifiers := map[string]registeredNotifier{}
+	kept := map[string]bool{}
+	var created []registeredNotifier
+	for _, config := range configs {
+		if registered, ok := d.notifiers[config.Name]; ok && reflect.DeepEqual(registered.config, config) {
+			notifiers[config.Name] = registered
+			kept[config.Name] = true
+			continue
+		}
+
+		notifier, err := newNotifier(config)
+		if err != nil {
+			closeNotifiers(created)
+			return err
+		}
+
+		registered := newRegisteredNotifier(notifier, config.Timeout, config.Retries)
+		registered.config = config
+		notifiers[config.Name] = registered
+		created = append(created, registered)
+	}
+
+	var replaced []registeredNotifier
+	for name, registered := range d.notifiers {
+		if kept[name] {
+			continue
+		}
+
+		if replacing, ok := notifiers[name]; ok {
+			if stateful, ok := replacing.notifier.(statefulNotifier); ok {
+				stateful.takeOver(registered.notifier)
+			}
+		}
+		replaced = append(replaced, registered)
+	}
+	closeNotifiers(replaced)
+
+	d.notifiers = notifiers
+	return nil
+}
+
+// Names returns the names of the notifiers, sorted.
+func (d *Dispatcher) Names() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	names := make([]string, 0, len(d.notifiers))
+	for name := range d.notifiers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Dispatch sends the event in the background to the named notifiers, or to every notifier when none is named.
+func (d *Dispatcher) Dispatch(event Event, names []string) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for name, registered := range d.notifiers {
+		if len(names) > 0 && !slices.Contains(names, name) {
+			continue
+		}
+
+		d.enqueue(queueKey{notifier: name, host: event.Host}, registered, event)
+	}
+}
+
+// enqueue delivers the event once the previous event of the host is delivered to the notifier. The event
+// waiting for delivery, if any, is dropped, as its state is outdated.
+func (d *Dispatcher) enqueue(key queueKey, registered registeredNotifier, event Event) {
+	d.queuesMu.Lock()
+	defer d.queuesMu.Unlock()
+
+	if queue, ok := d.queues[key]; ok {
+		if queue.pending != nil {
+			d.logger.Debugf("Dropped outdated event of [%s] for [%s].", key.host, key.notifier)
+			d.notifications.WithLabelValues(key.notifier, "dropped").Inc()
+		}
+		queue.registered = registered
+		queue.pending = &event
+		return
+	}
+
+	d.queues[key] = &eventQueue{}
+	d.wg.Add(1)
+	go d.drain(key, registered, event)
+}
+
+// drain delivers the event, then the events of the host queued in the meantime, until the queue is empty.
+func (d *Dispatcher) drain(key queueKey, registered registeredNotifier, event Event) {
+	defer d.wg.Done()
+
+	for {
+		d.deliver(key.notifier, registered, event)
+
+		d.queuesMu.Lock()
+		queue := d.queues[key]
+		if queue.pending == nil {
+			delete(d.queues, key)
+			d.queuesMu.Unlock()
+			return
+		}
+		registered, event = queue.registered, *queue.pending
+		queue.pending = nil
+		d.queuesMu.Unlock()
+	}
+}
+
+// Wait blocks until every event dispatched so far is delivered or given up on.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Shutdown waits for the events dispatched so far until the context is done, then releases the notifiers
+// holding resources. The events still being delivered once the context is done are given up on.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	delivered := make(chan struct{})
+	go func() {
+		d.Wait()
+		close(delivered)
+	}()
+
+	var errs []error
+	select {
+	case <-delivered:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("gave up on pending notifications: %w", ctx.Err()))
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return errors.Join(append(errs, closeNotifiers(slices.Collect(maps.Values(d.notifiers))))...)
+}
+
+// closeNotifiers releases the notifiers holding resources.
+func closeNotifiers(notifiers []registeredNotifier) error {
+	var errs []error
+	for _, registered := range notifiers {
+		if closer, ok := registered.notifier.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deliver sends the event to a notifier, retrying with an exponential backoff when it fails.
+func (d *Dispatcher) deliver(name string, registered registeredNotifier, event Event) {
+	delay := d.retryDelay
+
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), registered.timeout)
+		err := registered.notifier.Notify(ctx, event)
+		cancel()
+
+		if err == nil {
+			d.logger.Debugf("Notified [%s] that [%s] is %s.", name, event.Host, event.NewState)
+			d.notifications.WithLabelValues(name, "success").Inc()
+			return
+		}
+
+		if attempt >= registered.retries {
+			d.logger.WithError(err).Errorf("Failed to notify [%s] that [%s] is %s.", name, event.Host, event.NewState)
+			d.notifications.WithLabelValues(name, "failure").Inc()
+			return
+		}
+
+		d.logger.WithError(err).Debugf("Failed to notify [%s], retrying in [%s].", name, delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
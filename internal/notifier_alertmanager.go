@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Defaults of the Alertmanager notifiers.
+const (
+	alertmanagerAlertsPath      = "/api/v2/alerts"
+	defaultAlertName            = "UptimerHostDown"
+	defaultAlertResendInterval  = 60
+	alertmanagerResendsPerAlert = 4
+)
+
+// AlertmanagerOptions holds the options specific to Alertmanager notifiers.
+type AlertmanagerOptions struct {
+	// AlertName is the alertname label of the alerts. Defaults to UptimerHostDown.
+	AlertName string
+	// Labels are added to the labels of every alert, e.g. to route them.
+	Labels map[string]string
+	// ResendInterval is the interval between two sends of a firing alert, in seconds. Defaults to 60.
+	ResendInterval int
+}
+
+// alertmanagerAlert is an alert as posted to the Alertmanager API v2.
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
+
+// AlertmanagerNotifier is the implementation of the Notifier interface posting alerts to Alertmanager.
+// Alertmanager resolves the alerts which are not sent again before they end, so firing alerts are sent
+// again periodically until the host comes back up.
+type AlertmanagerNotifier struct {
+	logger         *logrus.Entry
+	httpClient     *http.Client
+	url            string
+	alertName      string
+	labels         map[string]string
+	resendInterval time.Duration
+	mu             sync.Mutex
+	firing         map[string]alertmanagerAlert
+	stop           chan struct{}
+	stopOnce       sync.Once
+}
+
+// NewAlertmanagerNotifier creates a new AlertmanagerNotifier instance and starts sending the firing alerts again
+// in the background, until it is closed.
+func NewAlertmanagerNotifier(config NotifierConfig) (*AlertmanagerNotifier, error) {
+	if err := validateNotifierURL(config); err != nil {
+		return nil, err
+	}
+
+	endpoint := strings.TrimSuffix(config.URL, "/")
+	if !strings.HasSuffix(endpoint, alertmanagerAlertsPath) {
+		endpoint += alertmanagerAlertsPath
+	}
+
+	resendInterval := config.Alertmanager.ResendInterval
+	if resendInterval <= 0 {
+		resendInterval = defaultAlertResendInterval
+	}
+
+	notifier := &AlertmanagerNotifier{
+		logger: logrus.WithFields(logrus.Fields{
+			"component": "alertmanager-notifier",
+		}),
+		httpClient:     &http.Client{},
+		url:            endpoint,
+		alertName:      orDefault(config.Alertmanager.AlertName, defaultAlertName),
+		labels:         config.Alertmanager.Labels,
+		resendInterval: time.Duration(resendInterval) * time.Second,
+		firing:         map[string]alertmanagerAlert{},
+		stop:           make(chan struct{}),
+	}
+	go notifier.resendLoop()
+
+	return notifier, nil
+}
+
+// Notify posts a firing alert when the host goes down, and the resolved alert when it comes back up. The
+// alert of a host which is already firing keeps its labels and its start, e.g. on reminders.
+func (n *AlertmanagerNotifier) Notify(ctx context.Context, event Event) error {
+	alert := alertmanagerAlert{
+		Labels:      n.alertLabels(event.Host, event.Tags),
+		Annotations: map[string]string{},
+		StartsAt:    event.Timestamp,
+		EndsAt:      event.Timestamp,
+	}
+
+	n.mu.Lock()
+	firing, ok := n.firing[event.Host]
+	if ok {
+		alert.Labels = firing.Labels
+		alert.StartsAt = firing.StartsAt
+	}
+
+	if event.NewState == stateDown {
+		alert.Annotations["summary"] = event.title()
+		alert.Annotations["reason"] = event.Reason
+		alert.Annotations["latency"] = event.Latency.Round(time.Millisecond).String()
+		alert.EndsAt = event.Timestamp.Add(alertmanagerResendsPerAlert * n.resendInterval)
+		n.firing[event.Host] = alert
+	} else {
+		if ok {
+			alert.Annotations = firing.Annotations
+		} else {
+			alert.StartsAt = event.Timestamp.Add(-event.Duration)
+		}
+		delete(n.firing, event.Host)
+	}
+	n.mu.Unlock()
+
+	return postJSON(ctx, n.httpClient, n.url, nil, []alertmanagerAlert{alert})
+}
+
+// Close stops sending the firing alerts again.
+func (n *AlertmanagerNotifier) Close() error {
+	n.stopOnce.Do(func() {
+		close(n.stop)
+	})
+
+	return nil
+}
+
+// takeOver takes the firing alerts of the Alertmanager notifier it replaces, so that they are still sent again
+// and resolved once their host comes back up. They keep their labels, which identify them in Alertmanager.
+func (n *AlertmanagerNotifier) takeOver(previous Notifier) {
+	replaced, ok := previous.(*AlertmanagerNotifier)
+	if !ok {
+		return
+	}
+
+	replaced.mu.Lock()
+	firing := maps.Clone(replaced.firing)
+	replaced.mu.Unlock()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for host, alert := range firing {
+		if _, ok := n.firing[host]; !ok {
+			n.firing[host] = alert
+		}
+	}
+}
+
+// alertLabels returns the labels identifying the alert of a host. As labels can't hold lists, the tags of the
+// host are joined in the tags label, surrounded by commas as the tags of the Prometheus service discoveries,
+// so that routes can match a tag with e.g. tags=~".*,frontend,.*".
+func (n *AlertmanagerNotifier) alertLabels(host string, tags []string) map[string]string {
+	labels := maps.Clone(n.labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels["alertname"] = n.alertName
+	labels["host"] = host
+	if len(tags) > 0 {
+		labels["tags"] = "," + strings.Join(slices.Sorted(slices.Values(tags)), ",") + ","
+	}
+
+	return labels
+}
+
+// resendLoop sends the firing alerts again on every interval, extending their end.
+func (n *AlertmanagerNotifier) resendLoop() {
+	ticker := time.NewTicker(n.resendInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+			if err := n.resend(); err != nil {
+				n.logger.WithError(err).Errorf("Failed to send firing alerts to [%s].", n.url)
+			}
+		}
+	}
+}
+
+// resend sends the firing alerts again.
+func (n *AlertmanagerNotifier) resend() error {
+	n.mu.Lock()
+	endsAt := time.Now().Add(alertmanagerResendsPerAlert * n.resendInterval)
+	for host, alert := range n.firing {
+		alert.EndsAt = endsAt
+		n.firing[host] = alert
+	}
+	alerts := slices.Collect(maps.Values(n.firing))
+	n.mu.Unlock()
+
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), n.resendInterval)
+	defer cancel()
+
+	if err := postJSON(ctx, n.httpClient, n.url, nil, alerts); err != nil {
+		return fmt.Errorf("failed to send [%d] alerts: %w", len(alerts), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupAlertmanagerServer starts a server standing in for Alertmanager, recording the alerts it receives.
+func setupAlertmanagerServer(t *testing.T) (*httptest.Server, func() [][]alertmanagerAlert) {
+	var mu sync.Mutex
+	var requests [][]alertmanagerAlert
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/alerts", r.URL.Path)
+
+		var alerts []alertmanagerAlert
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&alerts))
+		mu.Lock()
+		requests = append(requests, alerts)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() [][]alertmanagerAlert {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func setupAlertmanagerNotifier(t *testing.T, url string) *AlertmanagerNotifier {
+	notifier, err := NewAlertmanagerNotifier(NotifierConfig{
+		Name: "alertmanager",
+		URL:  url,
+		Alertmanager: AlertmanagerOptions{
+			Labels:         map[string]string{"severity": "page"},
+			ResendInterval: 3600,
+		},
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = notifier.Close()
+	})
+
+	return notifier
+}
+
+func TestAlertmanagerNotifierWithDownThenUpExpectFiringThenResolved(t *testing.T) {
+	server, requests := setupAlertmanagerServer(t)
+	notifier := setupAlertmanagerNotifier(t, server.URL)
+
+	down := time.Now().Truncate(time.Second)
+	err := notifier.Notify(context.Background(), Event{
+		Host:      "http://example.com",
+		OldState:  stateUp,
+		NewState:  stateDown,
+		Reason:    reasonTimeout,
+		Timestamp: down,
+	})
+	assert.NoError(t, err)
+
+	up := down.Add(5 * time.Minute)
+	err = notifier.Notify(context.Background(), Event{
+		Host:      "http://example.com",
+		OldState:  stateDown,
+		NewState:  stateUp,
+		Timestamp: up,
+		Duration:  5 * time.Minute,
+	})
+	assert.NoError(t, err)
+
+	if assert.Len(t, requests(), 2) {
+		firing := requests()[0][0]
+		assert.Equal(t, map[string]string{
+			"alertname": "UptimerHostDown",
+			"host":      "http://example.com",
+			"severity":  "page",
+		}, firing.Labels)
+		assert.Equal(t, "timeout", firing.Annotations["reason"])
+		assert.True(t, firing.StartsAt.Equal(down))
+		assert.True(t, firing.EndsAt.After(up))
+
+		resolved := requests()[1][0]
+		assert.Equal(t, firing.Labels, resolved.Labels)
+		assert.True(t, resolved.StartsAt.Equal(down))
+		assert.True(t, resolved.EndsAt.Equal(up))
+	}
+}
+
+func TestAlertmanagerNotifierResendWithFiringAlertExpectExtended(t *testing.T) {
+	server, requests := setupAlertmanagerServer(t)
+	notifier := setupAlertmanagerNotifier(t, server.URL+"/api/v2/alerts")
+
+	err := notifier.Notify(context.Background(), Event{
+		Host:      "http://example.com",
+		OldState:  stateUp,
+		NewState:  stateDown,
+		Timestamp: time.Now().Add(-time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, notifier.resend())
+
+	if assert.Len(t, requests(), 2) {
+		assert.Len(t, requests()[1], 1)
+		assert.True(t, requests()[1][0].EndsAt.After(requests()[0][0].EndsAt))
+	}
+}
+
+func TestAlertmanagerNotifierResendWithoutFiringAlertExpectNothingSent(t *testing.T) {
+	server, requests := setupAlertmanagerServer(t)
+	notifier := setupAlertmanagerNotifier(t, server.URL)
+
+	assert.NoError(t, notifier.resend())
+	assert.Empty(t, requests())
+}
+
+func TestAlertmanagerNotifierWithReminderExpectStartKept(t *testing.T) {
+	server, requests := setupAlertmanagerServer(t)
+	notifier := setupAlertmanagerNotifier(t, server.URL)
+
+	down := time.Now().Truncate(time.Second).Add(-time.Hour)
+	err := notifier.Notify(context.Background(), Event{
+		Host:      "http://example.com",
+		Tags:      []string{"shop", "frontend"},
+		OldState:  stateUp,
+		NewState:  stateDown,
+		Reason:    reasonTimeout,
+		Timestamp: down,
+	})
+	assert.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), Event{
+		Kind:      eventReminder,
+		Host:      "http://example.com",
+		Tags:      []string{"shop", "frontend"},
+		OldState:  stateDown,
+		NewState:  stateDown,
+		Reason:    reasonTimeout,
+		Timestamp: down.Add(time.Hour),
+		Duration:  time.Hour,
+	})
+	assert.NoError(t, err)
+
+	if assert.Len(t, requests(), 2) {
+		firing := requests()[0][0]
+		assert.Equal(t, ",frontend,shop,", firing.Labels["tags"])
+
+		reminded := requests()[1][0]
+		assert.Equal(t, firing.Labels, reminded.Labels)
+		assert.True(t, reminded.StartsAt.Equal(down))
+		assert.True(t, reminded.EndsAt.After(firing.EndsAt))
+		assert.Contains(t, reminded.Annotations["summary"], "still down")
+	}
+	assert.True(t, notifier.firing["http://example.com"].StartsAt.Equal(down))
+}
+
+func TestDispatcherReconfigureWithChangedAlertmanagerExpectFiringAlertsKept(t *testing.T) {
+	server, requests := setupAlertmanagerServer(t)
+	config := NotifierConfig{Name: "alertmanager", Type: "alertmanager", URL: server.URL}
+	dispatcher := setupDispatcher(t)
+	assert.NoError(t, dispatcher.Reconfigure([]NotifierConfig{config}))
+
+	down := time.Now().Truncate(time.Second).Add(-time.Hour)
+	dispatcher.Dispatch(Event{
+		Host:      "http://example.com",
+		OldState:  stateUp,
+		NewState:  stateDown,
+		Timestamp: down,
+	}, nil)
+	dispatcher.Wait()
+
+	config.Alertmanager.ResendInterval = 3600
+	assert.NoError(t, dispatcher.Reconfigure([]NotifierConfig{config}))
+	notifier := dispatcher.notifiers["alertmanager"].notifier.(*AlertmanagerNotifier)
+	assert.Contains(t, notifier.firing, "http://example.com")
+
+	dispatcher.Dispatch(Event{
+		Host:      "http://example.com",
+		OldState:  stateDown,
+		NewState:  stateUp,
+		Timestamp: down.Add(time.Hour),
+	}, nil)
+	dispatcher.Wait()
+
+	if assert.Len(t, requests(), 2) {
+		resolved := requests()[1][0]
+		assert.Equal(t, requests()[0][0].Labels, resolved.Labels)
+		assert.True(t, resolved.StartsAt.Equal(down))
+	}
+	assert.Empty(t, notifier.firing)
+}
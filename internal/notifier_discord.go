@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title     string         `json:"title"`
+	Color     int            `json:"color"`
+	Fields    []discordField `json:"fields"`
+	Timestamp string         `json:"timestamp"`
+}
+
+// discordPayload is the message posted to Discord webhooks.
+type discordPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+// DiscordNotifier is the implementation of the Notifier interface for Discord webhooks.
+type DiscordNotifier struct {
+	httpClient *http.Client
+	url        string
+}
+
+// NewDiscordNotifier creates a new DiscordNotifier instance.
+func NewDiscordNotifier(config NotifierConfig) (*DiscordNotifier, error) {
+	if err := validateNotifierURL(config); err != nil {
+		return nil, err
+	}
+
+	return &DiscordNotifier{
+		httpClient: &http.Client{},
+		url:        config.URL,
+	}, nil
+}
+
+// Notify posts the event to the Discord webhook, as a colored embed listing its details.
+func (n *DiscordNotifier) Notify(ctx context.Context, event Event) error {
+	var fields []discordField
+	for _, field := range event.fields() {
+		fields = append(fields, discordField{Name: field.Name, Value: field.Value, Inline: field.Name != "Host"})
+	}
+
+	return postJSON(ctx, n.httpClient, n.url, nil, discordPayload{
+		Embeds: []discordEmbed{{
+			Title:     event.title(),
+			Color:     stateColor(event),
+			Fields:    fields,
+			Timestamp: event.Timestamp.Format(time.RFC3339),
+		}},
+	})
+}
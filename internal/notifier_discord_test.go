@@ -0,0 +1,22 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscordNotifierWithRecoveryExpectEmbed(t *testing.T) {
+	server, payload := setupNotifierServer(t)
+
+	notifier, err := NewDiscordNotifier(NotifierConfig{Name: "discord", URL: server.URL})
+	assert.NoError(t, err)
+	assert.NoError(t, notifier.Notify(context.Background(), recoveryEvent))
+
+	embed := (*payload)["embeds"].([]any)[0].(map[string]any)
+	assert.Equal(t, "http://example.com is back up after 5m3s", embed["title"])
+	assert.Equal(t, float64(colorUp), embed["color"])
+	assert.Equal(t, "2024-01-01T00:00:00Z", embed["timestamp"])
+	assert.Contains(t, embed["fields"], map[string]any{"name": "Outage duration", "value": "5m3s", "inline": true})
+}
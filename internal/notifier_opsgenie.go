@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultOpsgenieURL is the endpoint of the Opsgenie Alert API.
+const defaultOpsgenieURL = "https://api.opsgenie.com/v2/alerts"
+
+// maxOpsgenieMessageSize is the maximum length of the message of an Opsgenie alert.
+const maxOpsgenieMessageSize = 130
+
+// OpsgenieOptions holds the options specific to Opsgenie notifiers.
+type OpsgenieOptions struct {
+	// APIKey is the key of the Opsgenie API integration.
+	APIKey string
+	// Priority is the priority of the alerts, from P1 to P5. Defaults to P1.
+	Priority string
+}
+
+// opsgenieAlert is the alert created with the Opsgenie Alert API.
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source"`
+	Entity      string            `json:"entity"`
+	Details     map[string]string `json:"details"`
+}
+
+// opsgenieClose is the request closing an alert with the Opsgenie Alert API.
+type opsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note"`
+}
+
+// OpsgenieNotifier is the implementation of the Notifier interface for the Opsgenie Alert API.
+// An alert is created when a host goes down, and closed when it comes back up.
+type OpsgenieNotifier struct {
+	httpClient *http.Client
+	url        string
+	apiKey     string
+	priority   string
+}
+
+// NewOpsgenieNotifier creates a new OpsgenieNotifier instance.
+func NewOpsgenieNotifier(config NotifierConfig) (*OpsgenieNotifier, error) {
+	config.URL = orDefault(config.URL, defaultOpsgenieURL)
+	if err := validateNotifierURL(config); err != nil {
+		return nil, err
+	}
+	if config.Opsgenie.APIKey == "" {
+		return nil, fmt.Errorf("notifier [%s] requires an api key", config.Name)
+	}
+
+	priority := strings.ToUpper(orDefault(config.Opsgenie.Priority, "P1"))
+	if len(priority) != 2 || priority[0] != 'P' || priority[1] < '1' || priority[1] > '5' {
+		return nil, fmt.Errorf("unsupported priority [%s] for notifier [%s]", config.Opsgenie.Priority, config.Name)
+	}
+
+	return &OpsgenieNotifier{
+		httpClient: &http.Client{},
+		url:        strings.TrimSuffix(config.URL, "/"),
+		apiKey:     config.Opsgenie.APIKey,
+		priority:   priority,
+	}, nil
+}
+
+// Notify creates an alert when the host goes down, and closes it when the host comes back up.
+// Alerts are identified by an alias derived from the host.
+func (n *OpsgenieNotifier) Notify(ctx context.Context, event Event) error {
+	headers := map[string]string{"Authorization": "GenieKey " + n.apiKey}
+	alias := dedupKey(event.Host)
+
+	if event.NewState == stateUp {
+		return postJSON(ctx, n.httpClient, n.url+"/"+url.PathEscape(alias)+"/close?identifierType=alias", headers, opsgenieClose{
+			Source: "uptimer",
+			Note:   event.title(),
+		})
+	}
+
+	details := map[string]string{}
+	var description strings.Builder
+	for _, field := range event.fields() {
+		details[field.Name] = field.Value
+		fmt.Fprintf(&description, "%s: %s\n", field.Name, field.Value)
+	}
+
+	message := []rune(event.title())
+	if len(message) > maxOpsgenieMessageSize {
+		message = message[:maxOpsgenieMessageSize]
+	}
+
+	return postJSON(ctx, n.httpClient, n.url, headers, opsgenieAlert{
+		Message:     string(message),
+		Alias:       alias,
+		Description: description.String(),
+		Priority:    n.priority,
+		Source:      "uptimer",
+		Entity:      event.Host,
+		Details:     details,
+	})
+}
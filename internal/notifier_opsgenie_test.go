@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpsgenieNotifierWithDownThenUpExpectCreateThenClose(t *testing.T) {
+	var paths []string
+	var payloads []map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GenieKey key", r.Header.Get("Authorization"))
+
+		payload := map[string]any{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		paths = append(paths, r.URL.RequestURI())
+		payloads = append(payloads, payload)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier, err := NewOpsgenieNotifier(NotifierConfig{
+		Name:     "opsgenie",
+		URL:      server.URL + "/v2/alerts",
+		Opsgenie: OpsgenieOptions{APIKey: "key", Priority: "p2"},
+	})
+	assert.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), Event{
+		Host:     "http://example.com",
+		OldState: stateUp,
+		NewState: stateDown,
+		Reason:   reasonTimeout,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, notifier.Notify(context.Background(), recoveryEvent))
+
+	alias := dedupKey("http://example.com")
+	assert.Equal(t, []string{"/v2/alerts", "/v2/alerts/" + alias + "/close?identifierType=alias"}, paths)
+	assert.Equal(t, alias, payloads[0]["alias"])
+	assert.Equal(t, "P2", payloads[0]["priority"])
+	assert.Equal(t, "http://example.com is down (timeout)", payloads[0]["message"])
+	assert.Equal(t, "http://example.com is back up after 5m3s", payloads[1]["note"])
+}
+
+func TestNewOpsgenieNotifierWithInvalidOptionsExpectError(t *testing.T) {
+	_, err := NewOpsgenieNotifier(NotifierConfig{Name: "opsgenie"})
+	assert.Error(t, err)
+
+	_, err = NewOpsgenieNotifier(NotifierConfig{
+		Name:     "opsgenie",
+		Opsgenie: OpsgenieOptions{APIKey: "key", Priority: "P9"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// defaultPagerDutyURL is the endpoint of the PagerDuty Events API v2.
+const defaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyOptions holds the options specific to PagerDuty notifiers.
+type PagerDutyOptions struct {
+	// RoutingKey is the integration key of the PagerDuty service.
+	RoutingKey string
+	// Severity is the severity of the incidents: critical, error, warning or info. Defaults to critical.
+	Severity string
+}
+
+type pagerDutyDetails struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	Component     string            `json:"component"`
+	CustomDetails map[string]string `json:"custom_details"`
+}
+
+// pagerDutyPayload is the event sent to the PagerDuty Events API v2.
+type pagerDutyPayload struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyDetails `json:"payload,omitempty"`
+}
+
+// PagerDutyNotifier is the implementation of the Notifier interface for the PagerDuty Events API v2.
+// An incident is triggered when a host goes down, and resolved when it comes back up.
+type PagerDutyNotifier struct {
+	httpClient *http.Client
+	url        string
+	routingKey string
+	severity   string
+}
+
+// NewPagerDutyNotifier creates a new PagerDutyNotifier instance.
+func NewPagerDutyNotifier(config NotifierConfig) (*PagerDutyNotifier, error) {
+	config.URL = orDefault(config.URL, defaultPagerDutyURL)
+	if err := validateNotifierURL(config); err != nil {
+		return nil, err
+	}
+	if config.PagerDuty.RoutingKey == "" {
+		return nil, fmt.Errorf("notifier [%s] requires a routing key", config.Name)
+	}
+
+	severity := orDefault(config.PagerDuty.Severity, "critical")
+	if !slices.Contains([]string{"critical", "error", "warning", "info"}, severity) {
+		return nil, fmt.Errorf("unsupported severity [%s] for notifier [%s]", severity, config.Name)
+	}
+
+	return &PagerDutyNotifier{
+		httpClient: &http.Client{},
+		url:        config.URL,
+		routingKey: config.PagerDuty.RoutingKey,
+		severity:   severity,
+	}, nil
+}
+
+// Notify triggers an incident when the host goes down, and resolves it when the host comes back up.
+func (n *PagerDutyNotifier) Notify(ctx context.Context, event Event) error {
+	payload := pagerDutyPayload{
+		RoutingKey:  n.routingKey,
+		EventAction: "resolve",
+		DedupKey:    dedupKey(event.Host),
+	}
+
+	if event.NewState == stateDown {
+		details := map[string]string{}
+		for _, field := range event.fields() {
+			details[field.Name] = field.Value
+		}
+
+		payload.EventAction = "trigger"
+		payload.Payload = &pagerDutyDetails{
+			Summary:       event.title(),
+			Source:        event.Host,
+			Severity:      n.severity,
+			Timestamp:     event.Timestamp.Format(time.RFC3339),
+			Component:     "uptimer",
+			CustomDetails: details,
+		}
+	}
+
+	return postJSON(ctx, n.httpClient, n.url, nil, payload)
+}
+
+// dedupKey returns a stable identifier of the incidents of a host, so that the incident triggered when it
+// goes down is the one resolved when it comes back up.
+func dedupKey(host string) string {
+	sum := sha256.Sum256([]byte(host))
+	return "uptimer-" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagerDutyNotifierWithDownThenUpExpectTriggerThenResolve(t *testing.T) {
+	server, payload := setupNotifierServer(t)
+
+	notifier, err := NewPagerDutyNotifier(NotifierConfig{
+		Name:      "pagerduty",
+		URL:       server.URL,
+		PagerDuty: PagerDutyOptions{RoutingKey: "key"},
+	})
+	assert.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), Event{
+		Host:     "http://example.com",
+		OldState: stateUp,
+		NewState: stateDown,
+		Reason:   reasonTimeout,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "key", (*payload)["routing_key"])
+	assert.Equal(t, "trigger", (*payload)["event_action"])
+	assert.Equal(t, dedupKey("http://example.com"), (*payload)["dedup_key"])
+	details := (*payload)["payload"].(map[string]any)
+	assert.Equal(t, "http://example.com is down (timeout)", details["summary"])
+	assert.Equal(t, "critical", details["severity"])
+	assert.Equal(t, "timeout", details["custom_details"].(map[string]any)["Reason"])
+
+	*payload = map[string]any{}
+	assert.NoError(t, notifier.Notify(context.Background(), recoveryEvent))
+
+	assert.Equal(t, "resolve", (*payload)["event_action"])
+	assert.Equal(t, dedupKey("http://example.com"), (*payload)["dedup_key"])
+	assert.NotContains(t, *payload, "payload")
+}
+
+func TestNewPagerDutyNotifierWithInvalidOptionsExpectError(t *testing.T) {
+	_, err := NewPagerDutyNotifier(NotifierConfig{Name: "pagerduty"})
+	assert.Error(t, err)
+
+	_, err = NewPagerDutyNotifier(NotifierConfig{
+		Name:      "pagerduty",
+		PagerDuty: PagerDutyOptions{RoutingKey: "key", Severity: "urgent"},
+	})
+	assert.Error(t, err)
+}
+
+func TestDedupKeyWithSameHostExpectStable(t *testing.T) {
+	assert.Equal(t, dedupKey("http://example.com"), dedupKey("http://example.com"))
+	assert.NotEqual(t, dedupKey("http://example.com"), dedupKey("http://example.org"))
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Colors of the chat messages, by state.
+const (
+	colorUp   = 0x2EB67D
+	colorDown = 0xE01E5A
+)
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Fields []slackField `json:"fields"`
+	Ts     int64        `json:"ts"`
+}
+
+// slackPayload is the message posted to Slack incoming webhooks.
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// SlackNotifier is the implementation of the Notifier interface for Slack incoming webhooks.
+type SlackNotifier struct {
+	httpClient *http.Client
+	url        string
+}
+
+// NewSlackNotifier creates a new SlackNotifier instance.
+func NewSlackNotifier(config NotifierConfig) (*SlackNotifier, error) {
+	if err := validateNotifierURL(config); err != nil {
+		return nil, err
+	}
+
+	return &SlackNotifier{
+		httpClient: &http.Client{},
+		url:        config.URL,
+	}, nil
+}
+
+// Notify posts the event to the Slack webhook, as a message with a colored attachment listing its details.
+func (n *SlackNotifier) Notify(ctx context.Context, event Event) error {
+	var fields []slackField
+	for _, field := range event.fields() {
+		fields = append(fields, slackField{Title: field.Name, Value: field.Value, Short: field.Name != "Host"})
+	}
+
+	return postJSON(ctx, n.httpClient, n.url, nil, slackPayload{
+		Text: event.title(),
+		Attachments: []slackAttachment{{
+			Color:  hexColor(event),
+			Fields: fields,
+			Ts:     event.Timestamp.Unix(),
+		}},
+	})
+}
+
+// stateColor returns the color of the messages of an event.
+func stateColor(event Event) int {
+	if event.NewState == stateUp {
+		return colorUp
+	}
+
+	return colorDown
+}
+
+// hexColor returns the color of the messages of an event, as an hexadecimal string.
+func hexColor(event Event) string {
+	return fmt.Sprintf("#%06X", stateColor(event))
+}
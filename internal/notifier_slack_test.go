@@ -0,0 +1,21 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlackNotifierWithRecoveryExpectMessage(t *testing.T) {
+	server, payload := setupNotifierServer(t)
+
+	notifier, err := NewSlackNotifier(NotifierConfig{Name: "slack", URL: server.URL})
+	assert.NoError(t, err)
+	assert.NoError(t, notifier.Notify(context.Background(), recoveryEvent))
+
+	assert.Equal(t, "http://example.com is back up after 5m3s", (*payload)["text"])
+	attachment := (*payload)["attachments"].([]any)[0].(map[string]any)
+	assert.Equal(t, "#2EB67D", attachment["color"])
+	assert.Contains(t, attachment["fields"], map[string]any{"title": "Outage duration", "value": "5m3s", "short": true})
+}
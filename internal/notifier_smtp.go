@@ -0,0 +1,287 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// TLS modes of the SMTP notifier.
+const (
+	smtpTLSStartTLS = "starttls"
+	smtpTLSImplicit = "implicit"
+	smtpTLSNone     = "none"
+)
+
+// Default templates of the emails.
+const (
+	defaultSubjectTemplate = `[uptimer] {{.Title}}`
+	defaultTextTemplate    = `{{.Title}}
+{{range .Fields}}
+{{.Name}}: {{.Value}}{{end}}
+`
+	defaultHTMLTemplate = `<html>
+<body>
+<h2>{{.Title}}</h2>
+<table>
+{{- range .Fields}}
+<tr><th align="left">{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`
+)
+
+// SMTPOptions holds the options specific to SMTP notifiers.
+type SMTPOptions struct {
+	// Host is the address of the SMTP server.
+	Host string
+	// Port is the port of the SMTP server. Defaults to 465 with implicit TLS, 587 otherwise.
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	// TLS is the TLS mode, either starttls, implicit or none. Defaults to starttls.
+	TLS string
+	// CAFile is an optional PEM file of certificate authorities trusted in addition to the system ones.
+	CAFile string
+	// Subject, Text and HTML override the templates of the emails, using the text/template syntax.
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// emailData is the data the email templates are rendered with.
+type emailData struct {
+	Event
+	Title  string
+	Fields []eventField
+}
+
+// SMTPNotifier is the implementation of the Notifier interface sending emails over SMTP.
+type SMTPNotifier struct {
+	address   string
+	host      string
+	username  string
+	password  string
+	from      string
+	to        []string
+	tlsMode   string
+	tlsConfig *tls.Config
+	subject   *texttemplate.Template
+	text      *texttemplate.Template
+	html      *htmltemplate.Template
+}
+
+// NewSMTPNotifier creates a new SMTPNotifier instance. The templates are parsed upfront, so that errors are
+// reported on startup.
+func NewSMTPNotifier(config NotifierConfig) (*SMTPNotifier, error) {
+	options := config.SMTP
+	if options.Host == "" || options.From == "" || len(options.To) == 0 {
+		return nil, fmt.Errorf("notifier [%s] requires a host, a sender and at least one recipient", config.Name)
+	}
+
+	tlsMode := strings.ToLower(options.TLS)
+	if tlsMode == "" {
+		tlsMode = smtpTLSStartTLS
+	}
+	if tlsMode != smtpTLSStartTLS && tlsMode != smtpTLSImplicit && tlsMode != smtpTLSNone {
+		return nil, fmt.Errorf("unsupported tls mode [%s] for notifier [%s]", options.TLS, config.Name)
+	}
+
+	port := options.Port
+	if port == 0 {
+		port = 587
+		if tlsMode == smtpTLSImplicit {
+			port = 465
+		}
+	}
+
+	roots, err := loadCertPool(options.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate authorities for notifier [%s]: %w", config.Name, err)
+	}
+
+	subject, err := texttemplate.New("subject").Parse(orDefault(options.Subject, defaultSubjectTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template for notifier [%s]: %w", config.Name, err)
+	}
+	text, err := texttemplate.New("text").Parse(orDefault(options.Text, defaultTextTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid text template for notifier [%s]: %w", config.Name, err)
+	}
+	html, err := htmltemplate.New("html").Parse(orDefault(options.HTML, defaultHTMLTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid html template for notifier [%s]: %w", config.Name, err)
+	}
+
+	return &SMTPNotifier{
+		address:   net.JoinHostPort(options.Host, strconv.Itoa(port)),
+		host:      options.Host,
+		username:  options.Username,
+		password:  options.Password,
+		from:      options.From,
+		to:        options.To,
+		tlsMode:   tlsMode,
+		tlsConfig: &tls.Config{ServerName: options.Host, RootCAs: roots},
+		subject:   subject,
+		text:      text,
+		html:      html,
+	}, nil
+}
+
+// Notify sends the event by email to every recipient.
+func (n *SMTPNotifier) Notify(ctx context.Context, event Event) error {
+	message, err := n.render(event)
+	if err != nil {
+		return err
+	}
+
+	client, err := n.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if n.tlsMode == smtpTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("server does not support STARTTLS")
+		}
+		if err := client.StartTLS(n.tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if n.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.from); err != nil {
+		return err
+	}
+	for _, recipient := range n.to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// dial connects to the SMTP server, directly over TLS in implicit mode. The deadline of the context
+// applies to the whole session.
+func (n *SMTPNotifier) dial(ctx context.Context) (*smtp.Client, error) {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", n.address)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	if n.tlsMode == smtpTLSImplicit {
+		conn = tls.Client(conn, n.tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// render builds the email of the event, with a plain text and an HTML alternative.
+func (n *SMTPNotifier) render(event Event) ([]byte, error) {
+	data := emailData{Event: event, Title: event.title(), Fields: event.fields()}
+
+	var subject, text, html bytes.Buffer
+	if err := n.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := n.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text body: %w", err)
+	}
+	if err := n.html.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render html body: %w", err)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", n.from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())))
+	fmt.Fprintf(&message, "Date: %s\r\n", event.Timestamp.Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+// orDefault returns the value, or the fallback when the value is empty.
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
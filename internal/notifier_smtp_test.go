@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSMTPServer is a minimal SMTP server recording the sessions it receives.
+type fakeSMTPServer struct {
+	listener   net.Listener
+	tlsConfig  *tls.Config
+	mu         sync.Mutex
+	auth       string
+	recipients []string
+	data       string
+	usedTLS    bool
+}
+
+// setupSMTPServer starts a fake SMTP server. With implicit TLS, the listener itself is encrypted; otherwise the
+// server offers STARTTLS. The returned CA file trusts the certificate of the server.
+func setupSMTPServer(t *testing.T, implicit bool) (*fakeSMTPServer, string) {
+	tlsServer, caFile := setupTLSServer(t)
+	tlsConfig := &tls.Config{Certificates: tlsServer.TLS.Certificates}
+
+	var listener net.Listener
+	var err error
+	if implicit {
+		listener, err = tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	} else {
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	server := &fakeSMTPServer{listener: listener, tlsConfig: tlsConfig, usedTLS: implicit}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return server, caFile
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		s.mu.Lock()
+		switch command {
+		case "EHLO":
+			reply("250-localhost")
+			if _, ok := conn.(*tls.Conn); !ok {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 Ready to start TLS")
+			conn = tls.Server(conn, s.tlsConfig)
+			reader = bufio.NewReader(conn)
+			s.usedTLS = true
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(strings.Fields(line)[2])
+			s.auth = string(credentials)
+			reply("235 Authenticated")
+		case "MAIL":
+			reply("250 OK")
+		case "RCPT":
+			s.recipients = append(s.recipients, strings.Trim(strings.SplitN(line, ":", 2)[1], "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil || dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			s.mu.Unlock()
+			return
+		default:
+			reply("250 OK")
+		}
+		s.mu.Unlock()
+	}
+}
+
+func setupSMTPNotifier(t *testing.T, server *fakeSMTPServer, caFile string, options SMTPOptions) *SMTPNotifier {
+	options.Host = "127.0.0.1"
+	options.Port = server.port()
+	options.CAFile = caFile
+	options.From = "uptimer@example.com"
+	if options.To == nil {
+		options.To = []string{"ops@example.com"}
+	}
+
+	notifier, err := NewSMTPNotifier(NotifierConfig{Name: "email", SMTP: options})
+	assert.NoError(t, err)
+
+	return notifier
+}
+
+func TestSMTPNotifierWithStartTLSExpectEmail(t *testing.T) {
+	server, caFile := setupSMTPServer(t, false)
+	notifier := setupSMTPNotifier(t, server, caFile, SMTPOptions{
+		Username: "user",
+		Password: "secret",
+		To:       []string{"ops@example.com", "cto@example.com"},
+	})
+
+	err := notifier.Notify(context.Background(), recoveryEvent)
+	assert.NoError(t, err)
+
+	assert.True(t, server.usedTLS)
+	assert.Equal(t, "\x00user\x00secret", server.auth)
+	assert.Equal(t, []string{"ops@example.com", "cto@example.com"}, server.recipients)
+	assert.Contains(t, server.data, "Subject: [uptimer] http://example.com is back up after 5m3s\r\n")
+	assert.Contains(t, server.data, "To: ops@example.com, cto@example.com\r\n")
+	assert.Contains(t, server.data, "Content-Type: text/plain; charset=utf-8")
+	assert.Contains(t, server.data, "Outage duration: 5m3s")
+	assert.Contains(t, server.data, "Content-Type: text/html; charset=utf-8")
+	assert.Contains(t, server.data, "<h2>http://example.com is back up after 5m3s</h2>")
+}
+
+func TestSMTPNotifierWithImplicitTLSExpectEmail(t *testing.T) {
+	server, caFile := setupSMTPServer(t, true)
+	notifier := setupSMTPNotifier(t, server, caFile, SMTPOptions{TLS: "implicit"})
+
+	err := notifier.Notify(context.Background(), recoveryEvent)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"ops@example.com"}, server.recipients)
+	assert.Contains(t, server.data, "http://example.com is back up after 5m3s")
+}
+
+func TestSMTPNotifierWithCustomTemplatesExpectRendered(t *testing.T) {
+	server, caFile := setupSMTPServer(t, false)
+	notifier := setupSMTPNotifier(t, server, caFile, SMTPOptions{
+		Subject: "{{.Host}} went {{.NewState}}",
+		Text:    "Reason: {{.Reason}}",
+		HTML:    "<p>{{.Reason}}</p>",
+	})
+
+	err := notifier.Notify(context.Background(), Event{
+		Host:     "http://example.com",
+		OldState: stateUp,
+		NewState: stateDown,
+		Reason:   "<timeout>",
+	})
+	assert.NoError(t, err)
+
+	assert.Contains(t, server.data, "Subject: http://example.com went down\r\n")
+	assert.Contains(t, server.data, "Reason: <timeout>")
+	assert.Contains(t, server.data, "<p>&lt;timeout&gt;</p>")
+}
+
+func TestNewSMTPNotifierWithInvalidOptionsExpectError(t *testing.T) {
+	valid := SMTPOptions{Host: "localhost", From: "uptimer@example.com", To: []string{"ops@example.com"}}
+
+	for name, mutate := range map[string]func(*SMTPOptions){
+		"no recipient":     func(o *SMTPOptions) { o.To = nil },
+		"unknown tls mode": func(o *SMTPOptions) { o.TLS = "ssl" },
+		"invalid template": func(o *SMTPOptions) { o.Subject = "{{.Host" },
+	} {
+		options := valid
+		mutate(&options)
+
+		_, err := NewSMTPNotifier(NotifierConfig{Name: "email", SMTP: options})
+		assert.Error(t, err, name)
+	}
+}
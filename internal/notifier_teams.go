@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type teamsSection struct {
+	Facts []teamsFact `json:"facts"`
+}
+
+// teamsPayload is the message card posted to Microsoft Teams connectors.
+type teamsPayload struct {
+	Type       string         `json:"@type"`
+	Context    string         `json:"@context"`
+	ThemeColor string         `json:"themeColor"`
+	Summary    string         `json:"summary"`
+	Title      string         `json:"title"`
+	Sections   []teamsSection `json:"sections"`
+}
+
+// TeamsNotifier is the implementation of the Notifier interface for Microsoft Teams connectors.
+type TeamsNotifier struct {
+	httpClient *http.Client
+	url        string
+}
+
+// NewTeamsNotifier creates a new TeamsNotifier instance.
+func NewTeamsNotifier(config NotifierConfig) (*TeamsNotifier, error) {
+	if err := validateNotifierURL(config); err != nil {
+		return nil, err
+	}
+
+	return &TeamsNotifier{
+		httpClient: &http.Client{},
+		url:        config.URL,
+	}, nil
+}
+
+// Notify posts the event to the Teams connector, as a message card listing its details.
+func (n *TeamsNotifier) Notify(ctx context.Context, event Event) error {
+	var facts []teamsFact
+	for _, field := range event.fields() {
+		facts = append(facts, teamsFact{Name: field.Name, Value: field.Value})
+	}
+
+	return postJSON(ctx, n.httpClient, n.url, nil, teamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: fmt.Sprintf("%06X", stateColor(event)),
+		Summary:    event.title(),
+		Title:      event.title(),
+		Sections:   []teamsSection{{Facts: facts}},
+	})
+}
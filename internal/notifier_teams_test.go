@@ -0,0 +1,22 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamsNotifierWithRecoveryExpectMessageCard(t *testing.T) {
+	server, payload := setupNotifierServer(t)
+
+	notifier, err := NewTeamsNotifier(NotifierConfig{Name: "teams", URL: server.URL})
+	assert.NoError(t, err)
+	assert.NoError(t, notifier.Notify(context.Background(), recoveryEvent))
+
+	assert.Equal(t, "MessageCard", (*payload)["@type"])
+	assert.Equal(t, "2EB67D", (*payload)["themeColor"])
+	assert.Equal(t, "http://example.com is back up after 5m3s", (*payload)["title"])
+	section := (*payload)["sections"].([]any)[0].(map[string]any)
+	assert.Contains(t, section["facts"], map[string]any{"name": "Outage duration", "value": "5m3s"})
+}
@@ -0,0 +1,204 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingNotifier is a Notifier keeping the events it receives, failing the first attempts if asked to.
+type recordingNotifier struct {
+	mu       sync.Mutex
+	events   []Event
+	attempts int
+	failures int
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, event Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.attempts++
+	if n.attempts <= n.failures {
+		return errors.New("unavailable")
+	}
+
+	n.events = append(n.events, event)
+	return nil
+}
+
+func setupDispatcher(t *testing.T) *Dispatcher {
+	dispatcher, err := NewDispatcher(nil, prometheus.NewRegistry())
+	assert.NoError(t, err)
+	dispatcher.retryDelay = time.Millisecond
+
+	return dispatcher
+}
+
+func TestDispatcherWithEventExpectDelivered(t *testing.T) {
+	dispatcher := setupDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.Register("test", notifier, 1, 0)
+
+	dispatcher.Dispatch(Event{Host: "http://example.com", OldState: stateUp, NewState: stateDown}, nil)
+	dispatcher.Wait()
+
+	assert.Len(t, notifier.events, 1)
+	assert.Equal(t, stateDown, notifier.events[0].NewState)
+	assert.Equal(t, 1.0, testutil.ToFloat64(dispatcher.notifications.WithLabelValues("test", "success")))
+}
+
+func TestDispatcherWithFailuresExpectRetried(t *testing.T) {
+	dispatcher := setupDispatcher(t)
+	notifier := &recordingNotifier{failures: 2}
+	dispatcher.Register("test", notifier, 1, 2)
+
+	dispatcher.Dispatch(Event{Host: "http://example.com", OldState: stateUp, NewState: stateDown}, nil)
+	dispatcher.Wait()
+
+	assert.Equal(t, 3, notifier.attempts)
+	assert.Len(t, notifier.events, 1)
+}
+
+func TestDispatcherWithRetriesExhaustedExpectFailure(t *testing.T) {
+	dispatcher := setupDispatcher(t)
+	notifier := &recordingNotifier{failures: 5}
+	dispatcher.Register("test", notifier, 1, 1)
+
+	dispatcher.Dispatch(Event{Host: "http://example.com", OldState: stateUp, NewState: stateDown}, nil)
+	dispatcher.Wait()
+
+	assert.Equal(t, 2, notifier.attempts)
+	assert.Empty(t, notifier.events)
+	assert.Equal(t, 1.0, testutil.ToFloat64(dispatcher.notifications.WithLabelValues("test", "failure")))
+}
+
+func TestNewDispatcherWithUnknownTypeExpectError(t *testing.T) {
+	_, err := NewDispatcher([]NotifierConfig{{Name: "test", Type: "pigeon"}}, prometheus.NewRegistry())
+	assert.Error(t, err)
+}
+
+// setupNotifierServer starts a server standing in for a notification API, decoding the JSON documents it receives.
+func setupNotifierServer(t *testing.T) (*httptest.Server, *map[string]any) {
+	payload := map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &payload
+}
+
+// recoveryEvent is an event of a host recovering from a 5 minutes outage.
+var recoveryEvent = Event{
+	Host:      "http://example.com",
+	OldState:  stateDown,
+	NewState:  stateUp,
+	Latency:   120 * time.Millisecond,
+	Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	Duration:  5*time.Minute + 3*time.Second,
+}
+
+func TestDispatcherWithNamedNotifiersExpectOthersSkipped(t *testing.T) {
+	dispatcher := setupDispatcher(t)
+	ops := &recordingNotifier{}
+	dev := &recordingNotifier{}
+	dispatcher.Register("ops", ops, 1, 0)
+	dispatcher.Register("dev", dev, 1, 0)
+
+	dispatcher.Dispatch(Event{Host: "http://example.com", OldState: stateUp, NewState: stateDown}, []string{"ops"})
+	dispatcher.Wait()
+
+	assert.Len(t, ops.events, 1)
+	assert.Empty(t, dev.events)
+}
+
+func TestEventTitleWithRecoveryExpectOutageDuration(t *testing.T) {
+	assert.Equal(t, "http://example.com is back up after 5m3s", recoveryEvent.title())
+	assert.Equal(t, "http://example.com is down (timeout)", Event{Host: "http://example.com", NewState: stateDown, Reason: reasonTimeout}.title())
+	assert.Equal(t, "http://example.com is flapping", Event{Kind: eventFlappingStarted, Host: "http://example.com", NewState: stateDown}.title())
+	assert.Equal(t, "http://example.com stopped flapping and is up", Event{Kind: eventFlappingStopped, Host: "http://example.com", NewState: stateUp}.title())
+}
+
+func TestDispatcherReconfigureExpectUnchangedNotifiersKept(t *testing.T) {
+	dispatcher := setupDispatcher(t)
+	ops := NotifierConfig{Name: "ops", Type: "webhook", URL: "http://localhost/ops"}
+	assert.NoError(t, dispatcher.Reconfigure([]NotifierConfig{ops}))
+	kept := dispatcher.notifiers["ops"].notifier
+
+	// an invalid configuration leaves the notifiers untouched
+	assert.Error(t, dispatcher.Reconfigure([]NotifierConfig{ops, {Name: "dev", Type: "unknown"}}))
+	assert.Equal(t, []string{"ops"}, slices.Sorted(maps.Keys(dispatcher.notifiers)))
+
+	assert.NoError(t, dispatcher.Reconfigure([]NotifierConfig{ops, {Name: "dev", Type: "webhook", URL: "http://localhost/dev"}}))
+	assert.Same(t, kept, dispatcher.notifiers["ops"].notifier)
+	assert.Equal(t, []string{"dev", "ops"}, slices.Sorted(maps.Keys(dispatcher.notifiers)))
+
+	ops.URL = "http://localhost/other"
+	assert.NoError(t, dispatcher.Reconfigure([]NotifierConfig{ops}))
+	assert.NotSame(t, kept, dispatcher.notifiers["ops"].notifier)
+	assert.Equal(t, []string{"ops"}, slices.Sorted(maps.Keys(dispatcher.notifiers)))
+}
+
+// blockingNotifier is a Notifier which never completes before its context is done.
+type blockingNotifier struct{}
+
+func (n blockingNotifier) Notify(ctx context.Context, _ Event) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestDispatcherShutdownWithPendingEventExpectGivenUp(t *testing.T) {
+	dispatcher := setupDispatcher(t)
+	dispatcher.Register("slow", blockingNotifier{}, 60, 0)
+	dispatcher.Dispatch(Event{Host: "http://example.com", OldState: stateUp, NewState: stateDown}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, dispatcher.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestDispatcherWithFailedEventExpectEventsInOrder(t *testing.T) {
+	dispatcher := setupDispatcher(t)
+	dispatcher.retryDelay = 20 * time.Millisecond
+	notifier := &recordingNotifier{failures: 1}
+	dispatcher.Register("test", notifier, 1, 1)
+
+	dispatcher.Dispatch(Event{Host: "http://example.com", OldState: stateUp, NewState: stateDown}, nil)
+	dispatcher.Dispatch(Event{Kind: eventReminder, Host: "http://example.com", OldState: stateDown, NewState: stateDown}, nil)
+	dispatcher.Dispatch(Event{Host: "http://example.com", OldState: stateDown, NewState: stateUp}, nil)
+	dispatcher.Wait()
+
+	assert.Equal(t, 3, notifier.attempts)
+	assert.Len(t, notifier.events, 2)
+	assert.Equal(t, stateDown, notifier.events[0].NewState)
+	assert.Equal(t, stateUp, notifier.events[1].NewState)
+	assert.Equal(t, 1.0, testutil.ToFloat64(dispatcher.notifications.WithLabelValues("test", "dropped")))
+}
+
+func TestDispatcherWithEventsOfHostsExpectDeliveredIndependently(t *testing.T) {
+	dispatcher := setupDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.Register("test", notifier, 1, 0)
+
+	dispatcher.Dispatch(Event{Host: "http://a.example.com", OldState: stateUp, NewState: stateDown}, nil)
+	dispatcher.Dispatch(Event{Host: "http://b.example.com", OldState: stateUp, NewState: stateDown}, nil)
+	dispatcher.Wait()
+
+	assert.Len(t, notifier.events, 2)
+	assert.Zero(t, testutil.ToFloat64(dispatcher.notifications.WithLabelValues("test", "dropped")))
+	assert.Empty(t, dispatcher.queues)
+}
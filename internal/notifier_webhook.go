@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// webhookPayload is the JSON document posted by the WebhookNotifier.
+type webhookPayload struct {
+	Kind       string    `json:"kind"`
+	Host       string    `json:"host"`
+	OldState   string    `json:"old_state"`
+	NewState   string    `json:"new_state"`
+	Reason     string    `json:"reason,omitempty"`
+	LatencyMs  float64   `json:"latency_ms"`
+	DurationMs float64   `json:"duration_ms,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// WebhookNotifier is the implementation of the Notifier interface posting events as JSON to an HTTP endpoint.
+type WebhookNotifier struct {
+	httpClient *http.Client
+	url        string
+	headers    map[string]string
+}
+
+// NewWebhookNotifier creates a new WebhookNotifier instance.
+func NewWebhookNotifier(config NotifierConfig) (*WebhookNotifier, error) {
+	if err := validateNotifierURL(config); err != nil {
+		return nil, err
+	}
+
+	return &WebhookNotifier{
+		httpClient: &http.Client{},
+		url:        config.URL,
+		headers:    config.Headers,
+	}, nil
+}
+
+// Notify posts the event to the webhook. Any status code outside of the 2xx range is considered as a failure.
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	return postJSON(ctx, n.httpClient, n.url, n.headers, webhookPayload{
+		Kind:       event.Kind,
+		Host:       event.Host,
+		OldState:   event.OldState,
+		NewState:   event.NewState,
+		Reason:     event.Reason,
+		LatencyMs:  durationToMilliseconds(event.Latency),
+		DurationMs: durationToMilliseconds(event.Duration),
+		Timestamp:  event.Timestamp,
+	})
+}
+
+// validateNotifierURL checks that the url of a notifier is an absolute URL.
+func validateNotifierURL(config NotifierConfig) error {
+	if _, err := url.ParseRequestURI(config.URL); err != nil {
+		return fmt.Errorf("invalid url for notifier [%s]: %w", config.Name, err)
+	}
+
+	return nil
+}
+
+// postJSON posts a JSON document to an HTTP endpoint, failing on any status code outside of the 2xx range.
+func postJSON(ctx context.Context, httpClient *http.Client, url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code [%d]", res.StatusCode)
+	}
+
+	return nil
+}
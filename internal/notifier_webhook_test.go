@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifierWithEventExpectJSONPayload(t *testing.T) {
+	var payload map[string]any
+	var authorization string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(NotifierConfig{
+		Name:    "test",
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	})
+	assert.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), Event{
+		Host:      "http://example.com",
+		OldState:  stateUp,
+		NewState:  stateDown,
+		Reason:    reasonTimeout,
+		Latency:   1500 * time.Millisecond,
+		Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Bearer token", authorization)
+	assert.Equal(t, "http://example.com", payload["host"])
+	assert.Equal(t, "up", payload["old_state"])
+	assert.Equal(t, "down", payload["new_state"])
+	assert.Equal(t, "timeout", payload["reason"])
+	assert.Equal(t, 1500.0, payload["latency_ms"])
+	assert.Equal(t, "2024-01-01T00:00:00Z", payload["timestamp"])
+}
+
+func TestWebhookNotifierWithErrorStatusExpectError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(NotifierConfig{Name: "test", URL: server.URL})
+	assert.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), Event{Host: "http://example.com"})
+	assert.Error(t, err)
+}
+
+func TestNewWebhookNotifierWithInvalidURLExpectError(t *testing.T) {
+	_, err := NewWebhookNotifier(NotifierConfig{Name: "test", URL: "not a url"})
+	assert.Error(t, err)
+}
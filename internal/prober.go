@@ -0,0 +1,178 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultModule is the name of the probe module used when none is requested.
+const defaultModule = "default"
+
+// scrapeTimeoutHeader is the header in which Prometheus sends the timeout of the scrape, in seconds.
+const scrapeTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
+
+// Prober runs single checks on demand, for Prometheus to drive the checks of targets it discovers,
+// the way the blackbox exporter does. The checks are configured by modules: hosts without a URL,
+// the target of each probe being checked with the options of the requested module.
+type Prober struct {
+	logger   *logrus.Entry
+	defaults Host
+	mu       sync.RWMutex
+	modules  map[string]Host
+}
+
+// NewProber creates a new Prober instance. The defaults are used by the probes without a module, and can
+// be overridden by a module named default.
+func NewProber(modules []Host, defaults Host) *Prober {
+	prober := &Prober{
+		logger: logrus.WithFields(logrus.Fields{
+			"component": "prober",
+		}),
+		defaults: defaults,
+	}
+	prober.SetModules(modules)
+
+	return prober
+}
+
+// SetModules replaces the modules of the probes.
+func (p *Prober) SetModules(modules []Host) {
+	output := map[string]Host{defaultModule: p.defaults}
+	for _, module := range modules {
+		output[module.Name] = module
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.modules = output
+}
+
+// Routes returns the routes of the probe endpoint.
+func (p *Prober) Routes() []Route {
+	return []Route{
+		{Pattern: "GET /probe", Handler: http.HandlerFunc(p.handleProbe)},
+	}
+}
+
+// handleProbe checks the target with the requested module, and responds with the metrics of the check.
+func (p *Prober) handleProbe(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+		return
+	}
+	if _, err := url.ParseRequestURI(target); err != nil {
+		http.Error(w, fmt.Sprintf("invalid target [%s]: %v", target, err), http.StatusBadRequest)
+		return
+	}
+
+	name := r.URL.Query().Get("module")
+	if name == "" {
+		name = defaultModule
+	}
+	p.mu.RLock()
+	module, ok := p.modules[name]
+	p.mu.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown module [%s]", name), http.StatusBadRequest)
+		return
+	}
+
+	host := module
+	host.Name = target
+	host.Host = target
+	host.Timeout = probeTimeout(module.Timeout, r.Header.Get(scrapeTimeoutHeader))
+
+	registry := prometheus.NewRegistry()
+	if err := p.probe(r.Context(), host, registry); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// probe checks the host once, registering the metrics of the check on the registerer.
+func (p *Prober) probe(ctx context.Context, host Host, registerer prometheus.Registerer) error {
+	checker, err := newChecker(host, registerer)
+	if err != nil {
+		return err
+	}
+	if closer, ok := checker.(io.Closer); ok {
+		defer func() {
+			_ = closer.Close()
+		}()
+	}
+
+	success := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "probe_success",
+		Help: "Whether the probe succeeded or not.",
+	})
+	duration := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "probe_duration_seconds",
+		Help: "The duration of the probe, in seconds.",
+	})
+	statusCode := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "probe_status_code",
+		Help: "The status code of the probe request, if any.",
+	})
+	failureReason := promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "probe_failure_reason",
+		Help: "The reason why the probe failed. Set to 1 for the reason, absent when the probe succeeded.",
+	}, []string{"reason"})
+
+	if host.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(host.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result := checker.Check(ctx)
+	duration.Set(time.Since(start).Seconds())
+	statusCode.Set(float64(result.StatusCode))
+
+	if result.Up {
+		success.Set(1)
+		return nil
+	}
+
+	reason := result.Reason
+	if reason == "" {
+		reason = classifyError(result.Err)
+	}
+	failureReason.WithLabelValues(reason).Set(1)
+	p.logger.Debugf("Probe of [%s] failed with reason [%s]: %v", host.Host, reason, result.Err)
+
+	return nil
+}
+
+// probeTimeout returns the timeout of a probe, in seconds: the timeout of the module, bounded by the
+// timeout of the scrape when Prometheus sends it.
+func probeTimeout(timeout int, scrapeTimeout string) int {
+	seconds, err := strconv.ParseFloat(scrapeTimeout, 64)
+	if err != nil || seconds < 1 {
+		return timeout
+	}
+
+	// the timeouts of the checks are in whole seconds, rounded down to respond before the scrape times out
+	bound := int(math.Floor(seconds))
+	if timeout <= 0 || bound < timeout {
+		return bound
+	}
+
+	return timeout
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// probe requests a probe of the target with the module, returning the status code and the body of the response.
+func probe(t *testing.T, prober *Prober, target, module string) (int, string) {
+	server := setupAPIServer(t, prober.Routes())
+
+	query := url.Values{}
+	query.Set("target", target)
+	if module != "" {
+		query.Set("module", module)
+	}
+
+	res, err := http.Get(server.URL + "/probe?" + query.Encode())
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	return res.StatusCode, string(body)
+}
+
+func TestProberWithDefaultModuleExpectSuccess(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	prober := NewProber(nil, Host{Timeout: 1})
+	status, body := probe(t, prober, target.URL, "")
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "probe_success 1")
+	assert.Contains(t, body, "probe_status_code 200")
+	assert.Contains(t, body, "probe_duration_seconds")
+	assert.NotContains(t, body, "probe_failure_reason")
+}
+
+func TestProberWithModuleAssertionsExpectFailureReason(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("maintenance"))
+	}))
+	defer target.Close()
+
+	prober := NewProber([]Host{{
+		Name:       "healthy",
+		Timeout:    1,
+		Assertions: AssertionOptions{Contains: []string{"healthy"}},
+	}}, Host{Timeout: 1})
+	status, body := probe(t, prober, target.URL, "healthy")
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "probe_success 0")
+	assert.Contains(t, body, `probe_failure_reason{reason="assertion_failed"} 1`)
+}
+
+func TestProberWithInvalidRequestsExpectBadRequest(t *testing.T) {
+	prober := NewProber(nil, Host{Timeout: 1})
+
+	status, _ := probe(t, prober, "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = probe(t, prober, "example.com", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = probe(t, prober, "http://example.com", "unknown")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = probe(t, prober, "ftp://example.com", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestProbeTimeout(t *testing.T) {
+	assert.Equal(t, 5, probeTimeout(5, ""))
+	assert.Equal(t, 5, probeTimeout(5, "invalid"))
+	assert.Equal(t, 5, probeTimeout(5, "0.5"))
+	assert.Equal(t, 3, probeTimeout(5, "3.5"))
+	assert.Equal(t, 5, probeTimeout(5, "10"))
+	assert.Equal(t, 10, probeTimeout(0, "10"))
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// Reasons reported when a check fails. They are used as label values, so they must stay stable.
+const (
+	reasonDNSError          = "dns_error"
+	reasonConnectionRefused = "connection_refused"
+	reasonUnreachable       = "unreachable"
+	reasonTimeout           = "timeout"
+	reasonTLSError          = "tls_error"
+	reasonReset             = "reset"
+	reasonBadStatus         = "bad_status"
+	reasonAssertionFailed   = "assertion_failed"
+	reasonError             = "error"
+	// reasonDependency is reported instead of the actual reason when a dependency of the host is down.
+	reasonDependency = "unreachable_due_to_dependency"
+)
+
+// classifyError returns the reason matching an error returned by a check. It falls back to reasonError
+// when the error is not recognized.
+func classifyError(err error) string {
+	if err == nil {
+		return reasonError
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return reasonDNSError
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, syscall.ETIMEDOUT) {
+		return reasonTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return reasonTimeout
+	}
+
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return reasonConnectionRefused
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return reasonUnreachable
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return reasonReset
+	}
+
+	if isTLSError(err) {
+		return reasonTLSError
+	}
+
+	return reasonError
+}
+
+// isTLSError returns whether the error happened during the TLS handshake or certificate verification.
+func isTLSError(err error) bool {
+	var (
+		verificationErr *tls.CertificateVerificationError
+		recordErr       tls.RecordHeaderError
+		alertErr        tls.AlertError
+		unknownAuthErr  x509.UnknownAuthorityError
+		invalidErr      x509.CertificateInvalidError
+		hostnameErr     x509.HostnameError
+	)
+
+	return errors.As(err, &verificationErr) ||
+		errors.As(err, &recordErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &unknownAuthErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.As(err, &hostnameErr)
+}
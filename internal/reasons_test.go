@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClassifyErrorWithConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	_, err = http.Get("http://" + address)
+	assert.Equal(t, reasonConnectionRefused, classifyError(err))
+}
+
+func TestClassifyErrorWithDNSError(t *testing.T) {
+	_, err := http.Get("http://host.invalid")
+	assert.Equal(t, reasonDNSError, classifyError(err))
+}
+
+func TestClassifyErrorWithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+	_, err := client.Get(server.URL)
+	assert.Equal(t, reasonTimeout, classifyError(err))
+
+	assert.Equal(t, reasonTimeout, classifyError(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)))
+}
+
+func TestClassifyErrorWithUntrustedCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := http.Get(server.URL)
+	assert.Equal(t, reasonTLSError, classifyError(err))
+}
+
+func TestClassifyErrorWithReset(t *testing.T) {
+	listener := setupTCPServer(t, func(conn net.Conn) {
+		// closing with a zero linger sends a RST instead of a FIN
+		_ = conn.(*net.TCPConn).SetLinger(0)
+	})
+	defer listener.Close()
+
+	_, err := http.Get("http://" + listener.Addr().String())
+	assert.Equal(t, reasonReset, classifyError(err))
+}
+
+func TestClassifyErrorWithUnknownError(t *testing.T) {
+	assert.Equal(t, reasonError, classifyError(errors.New("something happened")))
+	assert.Equal(t, reasonError, classifyError(nil))
+}
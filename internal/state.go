@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+// HostState is the state of a host, as last reported by its seeker.
+type HostState struct {
+	Up bool
+	// Reason is the failure reason of the last check, when it failed.
+	Reason     string
+	LastCheck  time.Time
+	Latency    time.Duration
+	StatusCode int
+	// ConsecutiveFailures is the number of failed checks in a row, reset by a successful check.
+	ConsecutiveFailures int
+	// LastChange is the time the host last changed state, or the time its seeker started.
+	LastChange  time.Time
+	Flapping    bool
+	Maintenance bool
+	// Escalated tells whether the outage of the host was escalated.
+	Escalated bool
+}
+
+// StateStore shares the state of the hosts between the seekers.
+type StateStore struct {
+	mu     sync.RWMutex
+	states map[string]HostState
+}
+
+// NewStateStore creates a new, empty, StateStore instance.
+func NewStateStore() *StateStore {
+	return &StateStore{
+		states: map[string]HostState{},
+	}
+}
+
+// Set stores the state of a host.
+func (s *StateStore) Set(name string, state HostState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[name] = state
+}
+
+// Delete forgets the state of a host which is not checked anymore.
+func (s *StateStore) Delete(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, name)
+}
+
+// Get returns the state of a host, and whether it was reported yet.
+func (s *StateStore) Get(name string) (HostState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.states[name]
+	return state, ok
+}
+
+// Down returns the hosts among the given ones which are down. Hosts which were not checked yet are
+// considered as up.
+func (s *StateStore) Down(names []string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var output []string
+	for _, name := range names {
+		if state, ok := s.states[name]; ok && !state.Up {
+			output = append(output, name)
+		}
+	}
+
+	return output
+}
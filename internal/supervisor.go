@@ -0,0 +1,531 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+// shutdownTimeout is the time given to the ongoing checks and the pending notifications on shutdown.
+const shutdownTimeout = 30 * time.Second
+
+// Config is the configuration applied by the supervisor, as parsed from the flags and the configuration file.
+type Config struct {
+	Hosts       []Host
+	Notifiers   []NotifierConfig
+	Maintenance []MaintenanceWindow
+	Modules     []Host
+}
+
+// trackingRegisterer remembers the collectors registered through it, so that they can be unregistered
+// once the seeker they belong to is stopped.
+type trackingRegisterer struct {
+	prometheus.Registerer
+	mu         sync.Mutex
+	collectors []prometheus.Collector
+}
+
+// Register registers the collector, and remembers it when it succeeds.
+func (r *trackingRegisterer) Register(collector prometheus.Collector) error {
+	if err := r.Registerer.Register(collector); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.collectors = append(r.collectors, collector)
+	return nil
+}
+
+// MustRegister registers the collectors, and panics when any of them fails to register.
+func (r *trackingRegisterer) MustRegister(collectors ...prometheus.Collector) {
+	for _, collector := range collectors {
+		if err := r.Register(collector); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// unregisterAll unregisters every collector registered so far.
+func (r *trackingRegisterer) unregisterAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, collector := range r.collectors {
+		r.Registerer.Unregister(collector)
+	}
+	r.collectors = nil
+}
+
+// runningSeeker is a seeker started by the supervisor, along with what is needed to stop it.
+type runningSeeker struct {
+	host       Host
+	seeker     *SeekerImpl
+	registerer *trackingRegisterer
+	cancel     context.CancelFunc
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// Supervisor runs a seeker for every host of the configuration, and applies the new configurations
+// on reload: the seekers of new hosts are started, those of removed hosts are stopped and the seekers
+// of changed hosts are restarted. The notifiers, maintenance windows and probe modules are replaced.
+// The seekers run within the root context of the supervisor, which is cancelled when it gives up on the checks
+// still ongoing at shutdown.
+type Supervisor struct {
+	logger      *logrus.Entry
+	ctx         context.Context
+	cancel      context.CancelFunc
+	registerer  prometheus.Registerer
+	dispatcher  *Dispatcher
+	maintenance *Maintenance
+	states      *StateStore
+	prober      *Prober
+	load        func() (Config, error)
+	reloadMu    sync.Mutex
+	// changeMu serializes the changes of the seekers, while mu guards the fields below and is released while
+	// waiting for the seekers to stop.
+	changeMu         sync.Mutex
+	mu               sync.Mutex
+	seekers          map[string]*runningSeeker
+	shutDown         bool
+	config           Config
+	monitors         map[string]Host
+	paused           map[string]bool
+	monitorsAPI      monitorsAPIOptions
+	reloadSuccessful prometheus.Gauge
+	reloadTimestamp  prometheus.Gauge
+}
+
+// SupervisorOption configures optional features of a Supervisor.
+type SupervisorOption func(*Supervisor)
+
+// NewSupervisor creates a new Supervisor instance, without any seeker. The configuration is loaded
+// again with the load function on every reload.
+func NewSupervisor(registerer prometheus.Registerer, prober *Prober, load func() (Config, error), options ...SupervisorOption) (*Supervisor, error) {
+	dispatcher, err := NewDispatcher(nil, registerer)
+	if err != nil {
+		return nil, err
+	}
+
+	maintenance, err := NewMaintenance(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	supervisor := &Supervisor{
+		logger: logrus.WithFields(logrus.Fields{
+			"component": "supervisor",
+		}),
+		ctx:         ctx,
+		cancel:      cancel,
+		registerer:  registerer,
+		dispatcher:  dispatcher,
+		maintenance: maintenance,
+		states:      NewStateStore(),
+		prober:      prober,
+		load:        load,
+		seekers:     map[string]*runningSeeker{},
+		monitors:    map[string]Host{},
+		paused:      map[string]bool{},
+		reloadSuccessful: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "uptime_config_last_reload_successful",
+			Help: "Whether the last configuration reload attempt was successful or not.",
+		}),
+		reloadTimestamp: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Name: "uptime_config_last_reload_success_timestamp_seconds",
+			Help: "The timestamp of the last successful configuration reload.",
+		}),
+	}
+	for _, option := range options {
+		option(supervisor)
+	}
+
+	return supervisor, nil
+}
+
+// Routes returns the routes of the probes, of the status API, and of the reload endpoint and the silences and
+// monitors APIs when they are enabled.
+func (s *Supervisor) Routes() []Route {
+	return slices.Concat(s.prober.Routes(), s.statusRoutes(), s.reloadRoutes(), s.silencesRoutes(), s.monitorsRoutes())
+}
+
+// reloadRoutes returns the route of the reload endpoint, which requires the token of the monitors API. It is
+// disabled without a token.
+func (s *Supervisor) reloadRoutes() []Route {
+	if s.monitorsAPI.token == "" {
+		return nil
+	}
+
+	return withToken(s.monitorsAPI.token, []Route{
+		{Pattern: "POST /-/reload", Handler: http.HandlerFunc(s.handleReload)},
+	})
+}
+
+// handleReload reloads the configuration, and reports whether it failed.
+func (s *Supervisor) handleReload(w http.ResponseWriter, _ *http.Request) {
+	if err := s.Reload(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+}
+
+// Reload loads the configuration again and applies it. The previous configuration keeps running when
+// the new one fails to load or to apply.
+func (s *Supervisor) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.logger.Info("Reloading the configuration.")
+
+	config, err := s.load()
+	if err != nil {
+		err = fmt.Errorf("failed to load the configuration: %w", err)
+		s.recordReload(err)
+		return err
+	}
+
+	return s.Apply(config)
+}
+
+// Apply validates the configuration, then applies it. Nothing is changed when the configuration is invalid.
+func (s *Supervisor) Apply(config Config) error {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.apply(config, s.monitors, s.paused)
+	s.recordReload(err)
+
+	return err
+}
+
+// apply validates, then applies the configuration along with the monitors created through the API. The
+// hosts which are paused are not checked. Both locks must be held.
+func (s *Supervisor) apply(config Config, monitors map[string]Host, paused map[string]bool) error {
+	if s.shutDown {
+		return errors.New("the supervisor is shut down")
+	}
+
+	all := slices.Concat(config.Hosts, slices.Collect(maps.Values(monitors)))
+	if err := validateUniqueHosts(all); err != nil {
+		return err
+	}
+	if err := validateDependencies(all); err != nil {
+		return fmt.Errorf("invalid host dependencies: %w", err)
+	}
+
+	notifiers := make([]string, 0, len(config.Notifiers))
+	for _, notifier := range config.Notifiers {
+		notifiers = append(notifiers, notifier.Name)
+	}
+
+	hosts := map[string]Host{}
+	for _, host := range all {
+		for _, name := range slices.Concat(host.Notifiers, host.Escalation.EscalateTo) {
+			if !slices.Contains(notifiers, name) {
+				return fmt.Errorf("unknown notifier [%s] for [%s]", name, host.Host)
+			}
+		}
+
+		if running, ok := s.seekers[host.Host]; !ok || !reflect.DeepEqual(running.host, host) {
+			if err := validateSeeker(host); err != nil {
+				return fmt.Errorf("invalid host [%s]: %w", host.Host, err)
+			}
+		}
+
+		if !paused[host.Name] {
+			hosts[host.Host] = host
+		}
+	}
+
+	if _, err := newMaintenanceWindows(config.Maintenance); err != nil {
+		return err
+	}
+
+	// the notifiers are replaced last, as nothing can fail once they are
+	if err := s.dispatcher.Reconfigure(config.Notifiers); err != nil {
+		return err
+	}
+	if err := s.maintenance.SetWindows(config.Maintenance); err != nil {
+		return err
+	}
+	s.prober.SetModules(config.Modules)
+
+	// the seekers of the changed hosts are replaced, carrying their state over, and the outages of the
+	// hosts which are not checked anymore are resolved
+	var stopped []*runningSeeker
+	for url, running := range s.seekers {
+		if host, ok := hosts[url]; ok && reflect.DeepEqual(running.host, host) {
+			continue
+		}
+
+		running.cancel()
+		stopped = append(stopped, running)
+	}
+	s.unlocked(func() {
+		for _, running := range stopped {
+			<-running.done
+		}
+	})
+
+	replaced := map[string]*SeekerImpl{}
+	for _, running := range stopped {
+		s.release(running)
+
+		host, ok := hosts[running.host.Host]
+		if !ok || host.Name != running.host.Name {
+			s.states.Delete(running.host.Name)
+		}
+		if ok {
+			replaced[host.Host] = running.seeker
+		} else {
+			running.seeker.resolve()
+		}
+	}
+
+	var errs []error
+	for url, host := range hosts {
+		if _, ok := s.seekers[url]; ok {
+			continue
+		}
+
+		if err := s.start(host, replaced[url]); err != nil {
+			if previous := replaced[url]; previous != nil {
+				previous.resolve()
+			}
+			errs = append(errs, fmt.Errorf("failed to start checking [%s]: %w", url, err))
+		}
+	}
+
+	s.config = config
+	s.monitors = monitors
+	s.paused = map[string]bool{}
+	for _, host := range all {
+		if paused[host.Name] {
+			s.paused[host.Name] = true
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateUniqueHosts checks that no two hosts share the same name or URL.
+func validateUniqueHosts(hosts []Host) error {
+	names := map[string]bool{}
+	urls := map[string]bool{}
+	for _, host := range hosts {
+		if names[host.Name] {
+			return fmt.Errorf("duplicate host name [%s]", host.Name)
+		}
+		if urls[host.Host] {
+			return fmt.Errorf("duplicate host [%s]", host.Host)
+		}
+		names[host.Name] = true
+		urls[host.Host] = true
+	}
+
+	return nil
+}
+
+// recordReload reports the outcome of a reload.
+func (s *Supervisor) recordReload(err error) {
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to apply the configuration. Keeping the previous one.")
+		s.reloadSuccessful.Set(0)
+		return
+	}
+
+	s.logger.Infof("Applied the configuration, checking [%d] hosts.", len(s.seekers))
+	s.reloadSuccessful.Set(1)
+	s.reloadTimestamp.SetToCurrentTime()
+}
+
+// start starts the seeker of the host, with the state of the seeker it replaces if any. Both locks must be held.
+func (s *Supervisor) start(host Host, previous *SeekerImpl) error {
+	registerer := &trackingRegisterer{
+		Registerer: prometheus.WrapRegistererWith(prometheus.Labels{"host": host.Host}, s.registerer),
+	}
+
+	seeker, err := NewSeeker(
+		host,
+		registerer,
+		WithDispatcher(s.dispatcher, host.Notifiers),
+		WithMaintenance(s.maintenance),
+		WithStates(s.states, host.DependsOn),
+		WithPrevious(previous),
+	)
+	if err != nil {
+		registerer.unregisterAll()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	running := &runningSeeker{
+		host:       host,
+		seeker:     seeker,
+		registerer: registerer,
+		cancel:     cancel,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	s.seekers[host.Host] = running
+
+	go func() {
+		defer close(running.done)
+		seeker.checkUntil(ctx, running.stop)
+	}()
+
+	s.logger.Infof("Started checking [%s]", host.Host)
+	return nil
+}
+
+// unlocked runs the function without holding the lock, e.g. to wait for seekers to stop, so that the monitors
+// and their status can still be read meanwhile. The changes are held off by changeMu. Both locks must be held.
+func (s *Supervisor) unlocked(f func()) {
+	s.mu.Unlock()
+	defer s.mu.Lock()
+
+	f()
+}
+
+// release releases the resources of a stopped seeker, and unregisters its metrics. The state of its host is
+// kept for the seeker replacing it, if any. Both locks must be held.
+func (s *Supervisor) release(running *runningSeeker) {
+	if err := running.seeker.Close(); err != nil {
+		s.logger.WithError(err).Warnf("Failed to close the checker of [%s].", running.host.Host)
+	}
+	running.registerer.unregisterAll()
+	delete(s.seekers, running.host.Host)
+
+	s.logger.Infof("Stopped checking [%s]", running.host.Host)
+}
+
+// Run serves the metrics and the routes of the supervisor until the context is done, then shuts down
+// within the shutdown timeout: the seekers are stopped once their ongoing check is over, the pending
+// notifications are delivered, and the HTTP server is shut down.
+func (s *Supervisor) Run(ctx context.Context, port int, registry *prometheus.Registry) error {
+	serverCtx, stopServer := context.WithCancel(context.Background())
+	defer stopServer()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(serverCtx, port, registry, s.Routes()...)
+	}()
+
+	var errs []error
+	select {
+	case err := <-served:
+		// the server failed to start, there is nothing to wait for
+		errs = append(errs, err)
+		served = nil
+	case <-ctx.Done():
+		s.logger.Info("Shutting down.")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	errs = append(errs, s.Shutdown(shutdownCtx))
+
+	if served != nil {
+		stopServer()
+		errs = append(errs, <-served)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Shutdown stops every seeker and waits for their ongoing checks until the context is done, when the checks
+// still ongoing are aborted, then delivers the pending notifications with the time left. The configuration
+// can't be applied anymore once shut down.
+func (s *Supervisor) Shutdown(ctx context.Context) error {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shutDown {
+		return nil
+	}
+	s.shutDown = true
+	stopped := slices.Collect(maps.Values(s.seekers))
+	for _, running := range stopped {
+		close(running.stop)
+	}
+
+	s.unlocked(func() {
+		for _, running := range stopped {
+			select {
+			case <-running.done:
+			case <-ctx.Done():
+			}
+		}
+	})
+
+	var errs []error
+	for _, running := range stopped {
+		select {
+		case <-running.done:
+			s.release(running)
+		default:
+			errs = append(errs, fmt.Errorf("gave up on the ongoing check of [%s]: %w", running.host.Host, ctx.Err()))
+		}
+	}
+	s.cancel()
+
+	if err := s.dispatcher.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// hookReload reloads the configuration on SIGHUP, until the context is done.
+func (s *Supervisor) hookReload(ctx context.Context) {
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(signalChan)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signalChan:
+				s.logger.Info("Received SIGHUP.")
+				_ = s.Reload()
+			}
+		}
+	}()
+}
+
+// validateSeeker creates the seeker of the host on a throwaway registry, so that an invalid host is
+// reported before any running seeker is stopped.
+func validateSeeker(host Host) error {
+	seeker, err := NewSeeker(host, prometheus.NewRegistry())
+	if err != nil {
+		return err
+	}
+
+	return seeker.Close()
+}
@@ -0,0 +1,379 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupSupervisor creates a supervisor loading its configuration with the given function.
+func setupSupervisor(t *testing.T, load func() (Config, error), options ...SupervisorOption) (*Supervisor, *prometheus.Registry) {
+	registry := prometheus.NewRegistry()
+	supervisor, err := NewSupervisor(registry, NewProber(nil, Host{}), load, options...)
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, supervisor.Shutdown(context.Background()))
+	})
+
+	return supervisor, registry
+}
+
+// monitoredHosts returns the hosts labelling the uptime_up metric.
+func monitoredHosts(t *testing.T, registry *prometheus.Registry) []string {
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	var output []string
+	for _, family := range families {
+		if family.GetName() != "uptime_up" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "host" {
+					output = append(output, label.GetValue())
+				}
+			}
+		}
+	}
+
+	return output
+}
+
+func TestSupervisorApplyExpectSeekersDiffed(t *testing.T) {
+	supervisor, registry := setupSupervisor(t, nil)
+	api := Host{Name: "api", Host: "http://api.example.com", Interval: 60, Timeout: 1}
+	web := Host{Name: "web", Host: "http://web.example.com", Interval: 60, Timeout: 1}
+
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{api, web}}))
+	assert.ElementsMatch(t, []string{api.Host, web.Host}, monitoredHosts(t, registry))
+	changed := supervisor.seekers[web.Host].seeker
+
+	web.Interval = 30
+	db := Host{Name: "db", Host: "tcp://db.example.com:5432", Interval: 60, Timeout: 1}
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{web, db}}))
+
+	assert.ElementsMatch(t, []string{web.Host, db.Host}, monitoredHosts(t, registry))
+	assert.NotContains(t, supervisor.seekers, api.Host)
+	assert.NotSame(t, changed, supervisor.seekers[web.Host].seeker)
+	assert.Equal(t, 30, supervisor.seekers[web.Host].seeker.interval)
+	unchanged := supervisor.seekers[web.Host].seeker
+
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{web, db}}))
+	assert.Same(t, unchanged, supervisor.seekers[web.Host].seeker)
+}
+
+func TestSupervisorApplyWithInvalidConfigExpectPreviousKept(t *testing.T) {
+	supervisor, registry := setupSupervisor(t, nil)
+	api := Host{Name: "api", Host: "http://api.example.com", Interval: 60, Timeout: 1}
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{api}}))
+	running := supervisor.seekers[api.Host].seeker
+
+	for name, config := range map[string]Config{
+		"unknown notifier":   {Hosts: []Host{{Name: "web", Host: "http://web.example.com", Interval: 60, Timeout: 1, Notifiers: []string{"ops"}}}},
+		"unknown dependency": {Hosts: []Host{{Name: "web", Host: "http://web.example.com", Interval: 60, Timeout: 1, DependsOn: []string{"gateway"}}}},
+		"invalid host":       {Hosts: []Host{{Name: "web", Host: "ftp://web.example.com", Interval: 60, Timeout: 1}}},
+		"invalid interval":   {Hosts: []Host{{Name: "web", Host: "http://web.example.com", Timeout: 1}}},
+		"invalid timeout":    {Hosts: []Host{{Name: "web", Host: "http://web.example.com", Interval: 60}}},
+		"invalid notifier":   {Hosts: []Host{api}, Notifiers: []NotifierConfig{{Name: "ops", Type: "unknown"}}},
+		"invalid window":     {Hosts: []Host{api}, Maintenance: []MaintenanceWindow{{Name: "invalid"}}},
+	} {
+		assert.Error(t, supervisor.Apply(config), name)
+		assert.Equal(t, 0.0, testutil.ToFloat64(supervisor.reloadSuccessful), name)
+		assert.Same(t, running, supervisor.seekers[api.Host].seeker, name)
+		assert.Equal(t, []string{api.Host}, monitoredHosts(t, registry), name)
+	}
+
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{api}}))
+	assert.Equal(t, 1.0, testutil.ToFloat64(supervisor.reloadSuccessful))
+}
+
+func TestSupervisorReloadEndpointExpectConfigurationLoaded(t *testing.T) {
+	config := Config{Hosts: []Host{{Name: "api", Host: "http://api.example.com", Interval: 60, Timeout: 1}}}
+	var loadErr error
+	supervisor, registry := setupSupervisor(t, func() (Config, error) {
+		return config, loadErr
+	}, WithMonitorsAPI(testAPIToken, Host{}, ""))
+	server := setupAPIServer(t, supervisor.Routes())
+
+	res, err := http.Post(server.URL+"/-/reload", "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Empty(t, monitoredHosts(t, registry))
+
+	status := callMonitorsAPI(t, http.MethodPost, server.URL+"/-/reload", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"http://api.example.com"}, monitoredHosts(t, registry))
+
+	loadErr = errors.New("invalid TOML")
+	status = callMonitorsAPI(t, http.MethodPost, server.URL+"/-/reload", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, 0.0, testutil.ToFloat64(supervisor.reloadSuccessful))
+	assert.Equal(t, []string{"http://api.example.com"}, monitoredHosts(t, registry))
+}
+
+func TestSupervisorReloadEndpointWithoutTokenExpectDisabled(t *testing.T) {
+	supervisor, _ := setupSupervisor(t, func() (Config, error) {
+		return Config{}, nil
+	})
+	server := setupAPIServer(t, supervisor.Routes())
+
+	res, err := http.Post(server.URL+"/-/reload", "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestSupervisorApplyWithRunningSeekerExpectChecksStopped(t *testing.T) {
+	checks := make(chan struct{}, 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checks <- struct{}{}
+	}))
+	defer server.Close()
+
+	supervisor, _ := setupSupervisor(t, nil)
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{{Name: "api", Host: server.URL, Interval: 1, Timeout: 1}}}))
+
+	select {
+	case <-checks:
+	case <-time.After(3 * time.Second):
+		t.Fatal("the host was not checked")
+	}
+
+	assert.NoError(t, supervisor.Apply(Config{}))
+	assert.Empty(t, supervisor.seekers)
+
+	select {
+	case <-checks:
+		t.Fatal("the host was checked once stopped")
+	case <-time.After(1500 * time.Millisecond):
+	}
+}
+
+func TestWatchConfigFileWithChangedFileExpectNotified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	assert.NoError(t, os.WriteFile(path, []byte("[hosts]"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 10)
+	assert.NoError(t, watchConfigFile(ctx, logger, path, func() {
+		changes <- struct{}{}
+	}))
+
+	// changes to other files of the directory are ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "other.toml"), []byte(""), 0o600))
+	assert.NoError(t, os.WriteFile(path, []byte("[hosts.api]"), 0o600))
+
+	select {
+	case <-changes:
+	case <-time.After(3 * time.Second):
+		t.Fatal("the change was not notified")
+	}
+
+	select {
+	case <-changes:
+		t.Fatal("the change was notified twice")
+	case <-time.After(2 * configWatchDelay):
+	}
+}
+
+func TestSupervisorRunWithCancelledContextExpectOrderedShutdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	supervisor, err := NewSupervisor(registry, NewProber(nil, Host{}), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{{Name: "api", Host: server.URL, Interval: 1, Timeout: 1}}}))
+	notifier := &recordingNotifier{}
+	supervisor.dispatcher.Register("ops", notifier, 1, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- supervisor.Run(ctx, 0, registry)
+	}()
+
+	// wait for the outage to be checked, then shut down
+	assert.Eventually(t, func() bool {
+		state, ok := supervisor.states.Get("api")
+		return ok && !state.Up
+	}, 3*time.Second, 10*time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the supervisor did not shut down")
+	}
+
+	// the seekers are stopped and the notification is delivered
+	assert.Empty(t, supervisor.seekers)
+	assert.Len(t, notifier.events, 1)
+	assert.Error(t, supervisor.Apply(Config{}))
+}
+
+func TestSupervisorShutdownWithOngoingCheckExpectCheckFinished(t *testing.T) {
+	started := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	supervisor, err := NewSupervisor(prometheus.NewRegistry(), NewProber(nil, Host{}), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{{Name: "api", Host: server.URL, Interval: 1, Timeout: 1}}}))
+	seeker := supervisor.seekers[server.URL].seeker
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, supervisor.Shutdown(ctx))
+
+	// the check is over and counted as up, instead of aborted
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.up))
+}
+
+func TestSupervisorShutdownWithExpiredDeadlineExpectCheckAborted(t *testing.T) {
+	started := make(chan struct{}, 1)
+	aborted := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-r.Context().Done()
+		close(aborted)
+	}))
+	defer server.Close()
+
+	supervisor, err := NewSupervisor(prometheus.NewRegistry(), NewProber(nil, Host{}), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{{Name: "api", Host: server.URL, Interval: 1, Timeout: 30}}}))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, supervisor.Shutdown(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-aborted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the ongoing check was not aborted")
+	}
+}
+
+// setupOutage returns a host failing until it is switched up, and a webhook notifier recording the events.
+func setupOutage(t *testing.T) (Host, *atomic.Bool, NotifierConfig, func() []webhookPayload) {
+	up := &atomic.Bool{}
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(target.Close)
+
+	var mu sync.Mutex
+	var payloads []webhookPayload
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		mu.Lock()
+		defer mu.Unlock()
+		payloads = append(payloads, payload)
+	}))
+	t.Cleanup(hook.Close)
+
+	received := func() []webhookPayload {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(payloads)
+	}
+
+	return Host{Name: "api", Host: target.URL, Interval: 60, Timeout: 1}, up,
+		NotifierConfig{Name: "ops", Type: "webhook", URL: hook.URL}, received
+}
+
+func TestSupervisorApplyWithChangedHostDownExpectRecoveryNotified(t *testing.T) {
+	supervisor, _ := setupSupervisor(t, nil)
+	host, up, notifier, received := setupOutage(t)
+
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{host}, Notifiers: []NotifierConfig{notifier}}))
+	_, err := supervisor.CheckMonitor(context.Background(), "api")
+	assert.NoError(t, err)
+
+	host.Interval = 30
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{host}, Notifiers: []NotifierConfig{notifier}}))
+	seeker := supervisor.seekers[host.Host].seeker
+	assert.False(t, seeker.notifiedUp)
+
+	// the state is carried over before the first check of the new seeker
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.up))
+	assert.Equal(t, 1, testutil.CollectAndCount(seeker.downReason))
+	state, ok := supervisor.states.Get("api")
+	assert.True(t, ok)
+	assert.False(t, state.Up)
+
+	up.Store(true)
+	_, err = supervisor.CheckMonitor(context.Background(), "api")
+	assert.NoError(t, err)
+	supervisor.dispatcher.Wait()
+
+	payloads := received()
+	assert.Len(t, payloads, 2)
+	assert.Equal(t, stateDown, payloads[0].NewState)
+	assert.Equal(t, eventTransition, payloads[1].Kind)
+	assert.Equal(t, stateUp, payloads[1].NewState)
+}
+
+func TestSupervisorApplyWithRemovedHostDownExpectOutageResolved(t *testing.T) {
+	supervisor, _ := setupSupervisor(t, nil)
+	host, _, notifier, received := setupOutage(t)
+
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{host}, Notifiers: []NotifierConfig{notifier}}))
+	_, err := supervisor.CheckMonitor(context.Background(), "api")
+	assert.NoError(t, err)
+
+	assert.NoError(t, supervisor.Apply(Config{Notifiers: []NotifierConfig{notifier}}))
+	supervisor.dispatcher.Wait()
+
+	payloads := received()
+	assert.Len(t, payloads, 2)
+	assert.Equal(t, stateDown, payloads[0].NewState)
+	assert.Equal(t, eventStopped, payloads[1].Kind)
+	assert.Equal(t, stateUp, payloads[1].NewState)
+}
+
+func TestSupervisorPauseMonitorWithHostDownExpectOutageResolved(t *testing.T) {
+	supervisor, _ := setupSupervisor(t, nil)
+	host, _, notifier, received := setupOutage(t)
+
+	assert.NoError(t, supervisor.Apply(Config{Hosts: []Host{host}, Notifiers: []NotifierConfig{notifier}}))
+	_, err := supervisor.CheckMonitor(context.Background(), "api")
+	assert.NoError(t, err)
+
+	_, err = supervisor.PauseMonitor("api", true)
+	assert.NoError(t, err)
+	supervisor.dispatcher.Wait()
+
+	payloads := received()
+	assert.Len(t, payloads, 2)
+	assert.Equal(t, eventStopped, payloads[1].Kind)
+}
@@ -2,45 +2,199 @@ package internal
 
 import (
 	"context"
+	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/sirupsen/logrus"
-	"net/http"
-	"net/http/cookiejar"
-	"os"
-	"os/signal"
-	"syscall"
+	"io"
+	"slices"
 	"time"
 )
 
-// UptimeChecker is the interface that defines the methods to check the uptime of a remote host.
-type UptimeChecker interface {
-	CheckUptime()
-	hookSignal(cancel context.CancelFunc)
-	check()
+// nativeHistogramBucketFactor is the growth factor between the buckets of native histograms.
+const nativeHistogramBucketFactor = 1.1
+
+// defaultCheckRetryDelay is the default delay between the attempts of a check.
+const defaultCheckRetryDelay = 500 * time.Millisecond
+
+// FlappingOptions holds the options of the flap detection.
+type FlappingOptions struct {
+	// Threshold is the number of state changes within the window above which the host is flapping. Disabled when 0.
+	Threshold int `json:"threshold,omitempty"`
+	// Window is the duration in seconds over which state changes are counted. The host stops flapping once
+	// it stays in the same state for a whole window.
+	Window int `json:"window,omitempty"`
+}
+
+// EscalationOptions holds the options of the reminders and escalation of outages.
+type EscalationOptions struct {
+	// ReminderInterval is the interval between reminders while the host stays down, in seconds. Disabled when 0.
+	ReminderInterval int `json:"reminder_interval,omitempty"`
+	// EscalateAfter is the duration of an outage before escalating it, in seconds. Disabled when 0.
+	EscalateAfter int `json:"escalate_after,omitempty"`
+	// EscalateTo are the names of the notifiers the outage is escalated to. They receive the following
+	// reminders and the recovery too, but no event before the escalation unless named in the notifiers of the host.
+	EscalateTo []string `json:"escalate_to,omitempty"`
+}
+
+// HistogramOptions holds the options of the latency histogram.
+type HistogramOptions struct {
+	// Buckets are the upper bounds of the classic histogram buckets, in seconds. Defaults to prometheus.DefBuckets.
+	Buckets []float64
+	// Native enables native histograms, exposed alongside the classic buckets.
+	Native bool
 }
 
-// SeekerImpl is the implementation of the UptimeChecker interface. It is responsible for checking the uptime of a remote host.
+// Seeker is the interface that defines the methods to periodically check the uptime of a remote host.
+type Seeker interface {
+	CheckUptime(ctx context.Context)
+	check(ctx context.Context) CheckResult
+}
+
+// SeekerImpl is the implementation of the Seeker interface. It is responsible for periodically running
+// an UptimeChecker against a remote host and reporting the results.
 type SeekerImpl struct {
-	logger       *logrus.Entry
-	httpClient   *http.Client
-	host         string
-	interval     int
-	up           prometheus.Gauge
-	latency      prometheus.Gauge
-	statusCode   prometheus.Gauge
-	previouslyUp bool
+	logger     *logrus.Entry
+	checker    UptimeChecker
+	name       string
+	host       string
+	tags       []string
+	interval   int
+	retries    int
+	retryDelay time.Duration
+	// failThreshold and recoverThreshold are the numbers of consecutive results required to change state.
+	failThreshold        int
+	recoverThreshold     int
+	consecutiveFailures  int
+	consecutiveSuccesses int
+	failingSince         time.Time
+	flapThreshold        int
+	flapWindow           time.Duration
+	transitions          []time.Time
+	flapping             bool
+	flappingGauge        prometheus.Gauge
+	up                   prometheus.Gauge
+	lastCheckUp          prometheus.Gauge
+	latency              prometheus.Gauge
+	histogram            *prometheus.HistogramVec
+	statusCode           prometheus.Gauge
+	downReason           *prometheus.GaugeVec
+	checks               *prometheus.CounterVec
+	dispatcher           *Dispatcher
+	notifiers            []string
+	maintenance          *Maintenance
+	states               *StateStore
+	dependsOn            []string
+	downDependencies     []string
+	reminderInterval     time.Duration
+	escalateAfter        time.Duration
+	escalateTo           []string
+	lastReminder         time.Time
+	escalated            bool
+	escalatedGauge       prometheus.Gauge
+	inMaintenance        bool
+	maintenanceGauge     prometheus.Gauge
+	lastReason           string
+	lastLatency          time.Duration
+	lastStatusCode       int
+	lastChange           time.Time
+	downSince            time.Time
+	previouslyUp         bool
+	// notifiedUp is the last state notified, which differs from the state when notifications are suppressed.
+	notifiedUp bool
+	// triggers receives the requests of immediate checks, answered with their result.
+	triggers chan chan CheckResult
+}
+
+// SeekerOption configures optional collaborators of a SeekerImpl.
+type SeekerOption func(*SeekerImpl)
+
+// WithMaintenance suppresses the notifications of the host while it is in maintenance.
+func WithMaintenance(maintenance *Maintenance) SeekerOption {
+	return func(s *SeekerImpl) {
+		s.maintenance = maintenance
+	}
+}
+
+// WithStates shares the state of the host through the store, and suppresses its failures while any
+// of the hosts it depends on is down.
+func WithStates(states *StateStore, dependsOn []string) SeekerOption {
+	return func(s *SeekerImpl) {
+		s.states = states
+		s.dependsOn = dependsOn
+	}
+}
+
+// WithDispatcher sends the state transitions of the host to the dispatcher, restricted to the
+// named notifiers when any.
+func WithDispatcher(dispatcher *Dispatcher, notifiers []string) SeekerOption {
+	return func(s *SeekerImpl) {
+		s.dispatcher = dispatcher
+		s.notifiers = notifiers
+	}
+}
+
+// WithPrevious carries over the state of the host from the seeker it replaces, e.g. when its options are
+// reloaded, so that an outage already notified is still resolved once the host recovers.
+func WithPrevious(previous *SeekerImpl) SeekerOption {
+	return func(s *SeekerImpl) {
+		if previous == nil {
+			return
+		}
+
+		s.previouslyUp = previous.previouslyUp
+		s.notifiedUp = previous.notifiedUp
+		s.consecutiveFailures = previous.consecutiveFailures
+		s.consecutiveSuccesses = previous.consecutiveSuccesses
+		s.failingSince = previous.failingSince
+		s.downSince = previous.downSince
+		s.lastChange = previous.lastChange
+		s.lastReason = previous.lastReason
+		s.lastReminder = previous.lastReminder
+		s.lastLatency = previous.lastLatency
+		s.lastStatusCode = previous.lastStatusCode
+		s.escalated = previous.escalated
+		if s.escalated {
+			s.escalatedGauge.Set(1)
+		}
+
+		// the gauges are left unset when the previous seeker counted no check yet
+		switch {
+		case !s.previouslyUp:
+			s.up.Set(0)
+			s.downReason.WithLabelValues(s.lastReason).Set(1)
+		case s.consecutiveFailures > 0 || s.consecutiveSuccesses > 0:
+			s.up.Set(1)
+		}
+	}
 }
 
 // NewSeeker creates a new SeekerImpl instance.
-func NewSeeker(host Host, registerer prometheus.Registerer) (*SeekerImpl, error) {
+func NewSeeker(host Host, registerer prometheus.Registerer, options ...SeekerOption) (*SeekerImpl, error) {
 	logger := logrus.WithFields(logrus.Fields{
 		"component": "seeker",
 	})
 
+	if host.Interval <= 0 {
+		return nil, fmt.Errorf("interval of [%s] must be positive, got [%d]", host.Host, host.Interval)
+	}
+	if host.Timeout <= 0 {
+		return nil, fmt.Errorf("timeout of [%s] must be positive, got [%d]", host.Host, host.Timeout)
+	}
+
+	checker, err := newChecker(host, registerer)
+	if err != nil {
+		return nil, err
+	}
+
 	upCounter := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
 		Name: "uptime_up",
-		Help: "Whether the host is up or not.",
+		Help: "Whether the host is up or not, once the failure and recovery thresholds are reached.",
+	})
+
+	lastCheckUp := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "uptime_last_check_up",
+		Help: "Whether the last check succeeded or not, regardless of the thresholds.",
 	})
 
 	latency := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
@@ -48,122 +202,544 @@ func NewSeeker(host Host, registerer prometheus.Registerer) (*SeekerImpl, error)
 		Help: "The latency between the server and the remote host.",
 	})
 
+	for i := 1; i < len(host.Histogram.Buckets); i++ {
+		if host.Histogram.Buckets[i] <= host.Histogram.Buckets[i-1] {
+			return nil, fmt.Errorf("latency buckets must be in increasing order, got %v", host.Histogram.Buckets)
+		}
+	}
+
+	if host.Flapping.Threshold > 0 && host.Flapping.Window <= 0 {
+		return nil, fmt.Errorf("flapping window of [%s] must be positive, got [%d]", host.Host, host.Flapping.Window)
+	}
+
+	histogramOpts := prometheus.HistogramOpts{
+		Name:    "uptime_latency_seconds",
+		Help:    "The latency of the checks, in seconds. Failed checks are observed with the failure outcome.",
+		Buckets: host.Histogram.Buckets,
+	}
+	if host.Histogram.Native {
+		histogramOpts.NativeHistogramBucketFactor = nativeHistogramBucketFactor
+		histogramOpts.NativeHistogramMaxBucketNumber = 100
+		histogramOpts.NativeHistogramMinResetDuration = time.Hour
+	}
+	histogram := promauto.With(registerer).NewHistogramVec(histogramOpts, []string{"outcome"})
+
+	flappingGauge := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "uptime_flapping",
+		Help: "Whether the host is flapping or not. Notifications of state changes are suppressed while flapping.",
+	})
+
+	escalatedGauge := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "uptime_escalated",
+		Help: "Whether the ongoing outage of the host was escalated or not.",
+	})
+
+	maintenanceGauge := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+		Name: "uptime_maintenance",
+		Help: "Whether the host is in maintenance or not. Notifications are suppressed during maintenance.",
+	})
+
 	statusCode := promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
 		Name: "uptime_status_code",
 		Help: "The status code of the last request.",
 	})
 
-	// create a cookie jar to store cookies
-	jar, err := cookiejar.New(nil)
-	if err != nil {
-		logger.Fatalf("Failed to create cookie jar: %v", err)
-		return nil, err
+	downReason := promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "uptime_down_reason",
+		Help: "The reason why the host is down. Set to 1 for the reason of the last check, absent when up.",
+	}, []string{"reason"})
+
+	checks := promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+		Name: "uptime_checks_total",
+		Help: "The number of checks performed, by result and failure reason.",
+	}, []string{"result", "reason"})
+
+	retryDelay := time.Duration(host.RetryDelay) * time.Millisecond
+	if retryDelay <= 0 {
+		retryDelay = defaultCheckRetryDelay
 	}
 
-	httpClient := &http.Client{
-		Timeout: time.Duration(host.Timeout) * time.Second,
-		Transport: &headerRoundTripper{
-			headers: host.Headers,
-			rt:      http.DefaultTransport,
-		},
-		Jar: jar,
+	seeker := &SeekerImpl{
+		logger:           logger,
+		checker:          checker,
+		name:             host.Name,
+		host:             host.Host,
+		tags:             host.Tags,
+		interval:         host.Interval,
+		retries:          max(host.Retries, 0),
+		retryDelay:       retryDelay,
+		failThreshold:    max(host.FailThreshold, 1),
+		recoverThreshold: max(host.RecoverThreshold, 1),
+		flapThreshold:    max(host.Flapping.Threshold, 0),
+		flapWindow:       time.Duration(host.Flapping.Window) * time.Second,
+		flappingGauge:    flappingGauge,
+		maintenanceGauge: maintenanceGauge,
+		reminderInterval: time.Duration(host.Escalation.ReminderInterval) * time.Second,
+		escalateAfter:    time.Duration(host.Escalation.EscalateAfter) * time.Second,
+		escalateTo:       host.Escalation.EscalateTo,
+		escalatedGauge:   escalatedGauge,
+		up:               upCounter,
+		lastCheckUp:      lastCheckUp,
+		latency:          latency,
+		histogram:        histogram,
+		statusCode:       statusCode,
+		downReason:       downReason,
+		checks:           checks,
+		previouslyUp:     true, // we assume the host is up when we start, to show an error if it's down
+		notifiedUp:       true,
+		lastChange:       time.Now(),
+		triggers:         make(chan chan CheckResult),
+	}
+	for _, option := range options {
+		option(seeker)
 	}
 
-	return &SeekerImpl{
-		logger:       logger,
-		httpClient:   httpClient,
-		host:         host.Host,
-		interval:     host.Interval,
-		up:           upCounter,
-		latency:      latency,
-		statusCode:   statusCode,
-		previouslyUp: true, // we assume the host is up when we start, to show an error if it's down
-	}, nil
+	return seeker, nil
 }
 
-// CheckUptime starts the uptime checking process. It will run indefinitely until the context is cancelled.
-func (s *SeekerImpl) CheckUptime() {
-	ctx, cancel := context.WithCancel(context.Background())
-	s.hookSignal(cancel)
-	defer cancel()
+// CheckUptime starts the uptime checking process. It will run until the context is cancelled, once the ongoing
+// check is over.
+func (s *SeekerImpl) CheckUptime(ctx context.Context) {
+	s.checkUntil(ctx, ctx.Done())
+}
 
+// checkUntil checks the host periodically until stopped or until the context is cancelled. Stopping lets the
+// ongoing check finish, while cancelling the context aborts it.
+func (s *SeekerImpl) checkUntil(ctx context.Context, stop <-chan struct{}) {
 	ticker := time.NewTicker(time.Duration(s.interval) * time.Second)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
 		select {
+		case <-stop:
+			return
 		case <-ctx.Done():
 			return
-		default:
-			s.check()
+		case <-ticker.C:
+			s.check(ctx)
+		case reply := <-s.triggers:
+			reply <- s.check(ctx)
 		}
 	}
 }
 
-// hookSignal hooks the SIGINT and SIGTERM signals to the context cancel function.
-func (s *SeekerImpl) hookSignal(cancel context.CancelFunc) {
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+// Trigger checks the host immediately, between the periodic checks, and returns the result. It waits until
+// the context is done for the seeker to run the check, which it only does while checking uptime.
+func (s *SeekerImpl) Trigger(ctx context.Context) (CheckResult, error) {
+	reply := make(chan CheckResult, 1)
+
+	select {
+	case s.triggers <- reply:
+	case <-ctx.Done():
+		return CheckResult{}, ctx.Err()
+	}
+
+	select {
+	case result := <-reply:
+		return result, nil
+	case <-ctx.Done():
+		return CheckResult{}, ctx.Err()
+	}
+}
+
+// Close releases the resources held by the checker, e.g. its connection to the remote host.
+func (s *SeekerImpl) Close() error {
+	if closer, ok := s.checker.(io.Closer); ok {
+		return closer.Close()
+	}
 
-	go func() {
-		<-signalChan
-		s.logger.Infof("Received signal. Stopping seeker for [%s].", s.host)
-		cancel()
-	}()
+	return nil
 }
 
-// check performs the actual check on the remote host. It will set the up and latency metrics accordingly.
-func (s *SeekerImpl) check() {
+// attempt runs the checker, retrying after the retry delay while it fails, until the context is done. It
+// returns the result of the last attempt, along with its duration.
+func (s *SeekerImpl) attempt(ctx context.Context) (CheckResult, time.Duration) {
 	start := time.Now()
+	result := s.checker.Check(ctx)
+	elapsed := time.Since(start)
 
-	s.logger.Debugf("Checking [%s]", s.host)
-	res, err := s.httpClient.Get(s.host)
-	if err != nil {
-		s.logger.Debugf("Got error [%v] for [%s]. Counting as down.", err, s.host)
-		s.up.Set(0)
-		if s.previouslyUp {
-			s.logger.Warnf("Host [%s] is down.", s.host)
+	for attempt := 1; !result.Up && attempt <= s.retries; attempt++ {
+		s.logger.Debugf("Got error [%v] for [%s]. Retrying (%d/%d).", result.Err, s.host, attempt, s.retries)
+		select {
+		case <-ctx.Done():
+			return result, elapsed
+		case <-time.After(s.retryDelay):
 		}
-		s.previouslyUp = false
 
-		return
+		attemptStart := time.Now()
+		result = s.checker.Check(ctx)
+		elapsed = time.Since(attemptStart)
 	}
 
-	// if the status code is not in the 2xx range, we consider the host as down
-	s.statusCode.Set(float64(res.StatusCode))
-	if res.StatusCode < 200 || res.StatusCode > 299 {
-		s.logger.Warnf("Got status code [%d] for [%s]. Counting as down.", res.StatusCode, s.host)
+	return result, elapsed
+}
+
+// check performs the actual check on the remote host. It will set the up and latency metrics accordingly,
+// and returns the result of the last attempt.
+func (s *SeekerImpl) check(ctx context.Context) CheckResult {
+	s.logger.Debugf("Checking [%s]", s.host)
+	s.updateMaintenance()
+	s.updateDependencies()
+
+	start := time.Now()
+	result, elapsed := s.attempt(ctx)
+	if err := ctx.Err(); err != nil {
+		// the seeker is stopped, the interrupted check doesn't count
+		s.logger.Debugf("Check of [%s] interrupted.", s.host)
+		return CheckResult{Err: err}
+	}
+
+	defer s.publishState()
+	defer s.remind()
+	defer s.reconcile()
+	defer s.updateFlapping()
+
+	if result.StatusCode != 0 {
+		s.statusCode.Set(float64(result.StatusCode))
+	}
+	s.lastStatusCode = result.StatusCode
+
+	if !result.Up {
+		reason := result.Reason
+		if reason == "" {
+			reason = classifyError(result.Err)
+		}
+		if len(s.downDependencies) > 0 {
+			reason = reasonDependency
+		}
+
+		s.logger.Debugf("Got error [%v] for [%s]. Counting as failed.", result.Err, s.host)
+		s.lastReason = reason
+		s.lastLatency = elapsed
+		result.Reason = reason
+		s.lastCheckUp.Set(0)
+		s.checks.WithLabelValues("failure", reason).Inc()
+		s.histogram.WithLabelValues("failure").Observe(elapsed.Seconds())
+
+		s.consecutiveSuccesses = 0
+		s.consecutiveFailures++
+		if s.consecutiveFailures == 1 {
+			s.failingSince = start
+		}
+		if s.previouslyUp && s.consecutiveFailures < s.failThreshold {
+			s.logger.Debugf("Host [%s] failed [%d/%d] consecutive checks. Still counting as up.", s.host, s.consecutiveFailures, s.failThreshold)
+			s.up.Set(1)
+			return result
+		}
+
 		s.up.Set(0)
+		s.downReason.Reset()
+		s.downReason.WithLabelValues(reason).Set(1)
 		if s.previouslyUp {
-			s.logger.Warnf("Host [%s] is down.", s.host)
+			s.logger.Warnf("Host [%s] is down (%s): %v.", s.host, reason, result.Err)
+			s.downSince = s.failingSince
+			s.lastChange = time.Now()
+			s.notify(stateUp, stateDown, reason, elapsed, 0)
 		}
 		s.previouslyUp = false
-		return
+
+		return result
 	}
 
-	s.logger.Debugf("Got status code [%d] for [%s]. Counting as up.", res.StatusCode, s.host)
-	s.up.Set(1)
-	s.latency.Set(float64(time.Since(start).Milliseconds()))
+	s.lastCheckUp.Set(1)
+	s.lastLatency = result.Latency
+	s.latency.Set(float64(result.Latency.Milliseconds()))
+	s.histogram.WithLabelValues("success").Observe(result.Latency.Seconds())
+	s.checks.WithLabelValues("success", "").Inc()
 
+	s.consecutiveFailures = 0
+	s.consecutiveSuccesses++
+	if !s.previouslyUp && s.consecutiveSuccesses < s.recoverThreshold {
+		s.logger.Debugf("Host [%s] passed [%d/%d] consecutive checks. Still counting as down.", s.host, s.consecutiveSuccesses, s.recoverThreshold)
+		return result
+	}
+
+	s.up.Set(1)
+	s.downReason.Reset()
 	if !s.previouslyUp {
 		s.logger.Infof("Host [%s] is online.", s.host)
+		s.lastChange = time.Now()
+		s.notify(stateDown, stateUp, "", result.Latency, time.Since(s.downSince))
 	}
 	s.previouslyUp = true
 
-	_ = res.Body.Close()
+	return result
 }
 
-// headerRoundTripper is a custom RoundTripper that adds headers to each request.
-type headerRoundTripper struct {
-	headers map[string]string
-	rt      http.RoundTripper
+// notify sends a state transition of the host to the dispatcher, if any. Transitions are suppressed
+// while the host is flapping.
+func (s *SeekerImpl) notify(oldState, newState, reason string, latency, duration time.Duration) {
+	event := Event{
+		Kind:      eventTransition,
+		Host:      s.host,
+		OldState:  oldState,
+		NewState:  newState,
+		Reason:    reason,
+		Latency:   latency,
+		Timestamp: time.Now(),
+		Duration:  duration,
+	}
+
+	if s.flapThreshold > 0 {
+		s.transitions = append(s.recentTransitions(event.Timestamp), event.Timestamp)
+
+		if !s.flapping && len(s.transitions) > s.flapThreshold {
+			s.flapping = true
+			s.flappingGauge.Set(1)
+			s.logger.Warnf("Host [%s] is flapping: [%d] state changes within [%s].", s.host, len(s.transitions), s.flapWindow)
+
+			event.Kind = eventFlappingStarted
+			s.dispatch(event)
+			return
+		}
+
+		if s.flapping {
+			s.logger.Debugf("Host [%s] is flapping. Not notifying that it is %s.", s.host, newState)
+			return
+		}
+	}
+
+	s.dispatch(event)
+}
+
+// updateFlapping stops the flapping of the host once it stayed in the same state for a whole window.
+func (s *SeekerImpl) updateFlapping() {
+	if !s.flapping {
+		return
+	}
+
+	now := time.Now()
+	s.transitions = s.recentTransitions(now)
+	if len(s.transitions) > 0 {
+		return
+	}
+
+	state := stateDown
+	if s.previouslyUp {
+		state = stateUp
+	}
+
+	s.flapping = false
+	s.flappingGauge.Set(0)
+	s.logger.Infof("Host [%s] stopped flapping and is %s.", s.host, state)
+	s.dispatch(Event{
+		Kind:      eventFlappingStopped,
+		Host:      s.host,
+		OldState:  state,
+		NewState:  state,
+		Timestamp: now,
+	})
+}
+
+// recentTransitions returns the transitions within the flapping window.
+func (s *SeekerImpl) recentTransitions(now time.Time) []time.Time {
+	var output []time.Time
+	for _, transition := range s.transitions {
+		if now.Sub(transition) < s.flapWindow {
+			output = append(output, transition)
+		}
+	}
+
+	return output
+}
+
+// updateMaintenance updates whether the host is in maintenance.
+func (s *SeekerImpl) updateMaintenance() {
+	if s.maintenance == nil {
+		return
+	}
+
+	inMaintenance := s.maintenance.Active(s.name, s.host, s.tags, time.Now())
+	if inMaintenance == s.inMaintenance {
+		return
+	}
+
+	s.inMaintenance = inMaintenance
+	if inMaintenance {
+		s.logger.Infof("Host [%s] entered maintenance. Notifications are suppressed.", s.host)
+		s.maintenanceGauge.Set(1)
+		return
+	}
+
+	s.logger.Infof("Host [%s] left maintenance.", s.host)
+	s.maintenanceGauge.Set(0)
+}
+
+// updateDependencies updates the dependencies of the host which are down.
+func (s *SeekerImpl) updateDependencies() {
+	if s.states == nil {
+		return
+	}
+
+	s.downDependencies = s.states.Down(s.dependsOn)
 }
 
-// RoundTrip executes a single HTTP transaction. It will add the headers to the request before sending it.
-func (hrt *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	for key, value := range hrt.headers {
-		req.Header.Set(key, value)
+// publishState shares the state of the host with the seekers depending on it.
+func (s *SeekerImpl) publishState() {
+	if s.states == nil {
+		return
+	}
+
+	state := HostState{
+		Up:                  s.previouslyUp,
+		LastCheck:           time.Now(),
+		Latency:             s.lastLatency,
+		StatusCode:          s.lastStatusCode,
+		ConsecutiveFailures: s.consecutiveFailures,
+		LastChange:          s.lastChange,
+		Flapping:            s.flapping,
+		Maintenance:         s.inMaintenance,
+		Escalated:           s.escalated,
+	}
+	if !state.Up || s.consecutiveFailures > 0 {
+		state.Reason = s.lastReason
 	}
+	s.states.Set(s.name, state)
+}
 
-	return hrt.rt.RoundTrip(req)
+// suppression returns why the notifications of the host are suppressed, or an empty string if they are not.
+func (s *SeekerImpl) suppression() string {
+	if s.inMaintenance {
+		return "it is in maintenance"
+	}
+	if len(s.downDependencies) > 0 {
+		return fmt.Sprintf("its dependencies %v are down", s.downDependencies)
+	}
+
+	return ""
+}
+
+// reconcile notifies the current state of the host if it differs from the last state notified, which
+// happens when a transition occurred while the notifications were suppressed.
+func (s *SeekerImpl) reconcile() {
+	if s.previouslyUp == s.notifiedUp || s.flapping || s.suppression() != "" {
+		return
+	}
+
+	event := Event{
+		Kind:      eventTransition,
+		Host:      s.host,
+		OldState:  stateUp,
+		NewState:  stateDown,
+		Reason:    s.lastReason,
+		Timestamp: time.Now(),
+	}
+	if s.previouslyUp {
+		event.OldState, event.NewState, event.Reason = stateDown, stateUp, ""
+		event.Duration = time.Since(s.downSince)
+	}
+	s.dispatch(event)
+}
+
+// remind sends a reminder while the host stays down, or escalates the outage once it lasted long enough.
+func (s *SeekerImpl) remind() {
+	if s.notifiedUp || s.previouslyUp || s.flapping || s.suppression() != "" {
+		return
+	}
+
+	now := time.Now()
+	event := Event{
+		Host:      s.host,
+		OldState:  stateDown,
+		NewState:  stateDown,
+		Reason:    s.lastReason,
+		Timestamp: now,
+		Duration:  now.Sub(s.downSince),
+	}
+
+	switch {
+	case s.escalateAfter > 0 && len(s.escalateTo) > 0 && !s.escalated && event.Duration >= s.escalateAfter:
+		s.logger.Warnf("Host [%s] is still down after [%s]. Escalating to %v.", s.host, event.Duration.Round(time.Second), s.escalateTo)
+		event.Kind = eventEscalation
+		s.dispatch(event)
+		s.escalated = true
+		s.escalatedGauge.Set(1)
+	case s.reminderInterval > 0 && now.Sub(s.lastReminder) >= s.reminderInterval:
+		s.logger.Infof("Host [%s] is still down after [%s]. Sending a reminder.", s.host, event.Duration.Round(time.Second))
+		event.Kind = eventReminder
+		s.dispatch(event)
+	}
+}
+
+// recipients returns the notifiers of an event: the escalation notifiers for an escalation, along with the
+// notifiers of the host once escalated. When the host names no notifiers, every notifier but the escalation
+// notifiers receives the event until the outage is escalated.
+func (s *SeekerImpl) recipients(event Event) []string {
+	if event.Kind == eventEscalation {
+		return s.escalateTo
+	}
+
+	notifiers := s.notifiers
+	if len(notifiers) == 0 {
+		notifiers = slices.DeleteFunc(s.dispatcher.Names(), func(name string) bool {
+			return slices.Contains(s.escalateTo, name)
+		})
+	}
+	if !s.escalated {
+		return notifiers
+	}
+
+	recipients := slices.Clone(notifiers)
+	for _, name := range s.escalateTo {
+		if !slices.Contains(recipients, name) {
+			recipients = append(recipients, name)
+		}
+	}
+
+	return recipients
+}
+
+// resolve notifies that the outage of the host is over when it stops being checked while notified down,
+// so that the incidents and alerts opened for it are not left open. The notifications are not suppressed.
+func (s *SeekerImpl) resolve() {
+	if s.notifiedUp || s.dispatcher == nil {
+		return
+	}
+
+	now := time.Now()
+	event := Event{
+		Kind:      eventStopped,
+		Host:      s.host,
+		Tags:      s.tags,
+		OldState:  stateDown,
+		NewState:  stateUp,
+		Timestamp: now,
+		Duration:  now.Sub(s.downSince),
+	}
+	s.logger.Infof("Host [%s] is no longer checked while down. Resolving its outage.", s.host)
+	s.send(event)
+	s.notifiedUp = true
+}
+
+// send sends the event to its recipients. Nothing is sent when the event has none, as the dispatcher sends
+// the events to every notifier when none is named.
+func (s *SeekerImpl) send(event Event) {
+	recipients := s.recipients(event)
+	if len(recipients) == 0 {
+		s.logger.Debugf("Not notifying that [%s] is %s, as no notifier receives it.", s.host, event.NewState)
+		return
+	}
+
+	s.dispatcher.Dispatch(event, recipients)
+}
+
+// dispatch sends an event to the dispatcher, if any. Events are suppressed while the host is in maintenance
+// or one of its dependencies is down.
+func (s *SeekerImpl) dispatch(event Event) {
+	if suppression := s.suppression(); suppression != "" {
+		s.logger.Debugf("Not notifying that [%s] is %s, as %s.", s.host, event.NewState, suppression)
+		return
+	}
+
+	event.Tags = s.tags
+	s.notifiedUp = event.NewState == stateUp
+	s.lastReminder = event.Timestamp
+	if s.dispatcher != nil {
+		s.send(event)
+	}
+
+	if s.notifiedUp && s.escalated {
+		s.escalated = false
+		s.escalatedGauge.Set(0)
+	}
 }
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,9 @@ func setupSeeker(server *httptest.Server) *SeekerImpl {
 	registerer := prometheus.NewRegistry()
 	seeker, err := NewSeeker(
 		Host{
-			Host: server.URL,
+			Host:     server.URL,
+			Interval: 60,
+			Timeout:  1,
 		},
 		registerer,
 	)
@@ -47,10 +50,10 @@ func TestSeekerPersistCookieJar(t *testing.T) {
 
 	seeker := setupSeeker(server)
 	// First request to set the cookie
-	seeker.check()
+	seeker.check(context.Background())
 
 	// Second request to check if the cookie is persisted
-	seeker.check()
+	seeker.check(context.Background())
 }
 
 func TestSeekerImplCheckUptimeWithSuccessExpectUp(t *testing.T) {
@@ -61,7 +64,7 @@ func TestSeekerImplCheckUptimeWithSuccessExpectUp(t *testing.T) {
 	defer server.Close()
 
 	seeker := setupSeeker(server)
-	seeker.check()
+	seeker.check(context.Background())
 
 	upGauge := testutil.ToFloat64(seeker.up)
 	if upGauge != 1 {
@@ -81,7 +84,7 @@ func TestSeekerImplCheckUptimeWith400ExpectDown(t *testing.T) {
 	defer server.Close()
 
 	seeker := setupSeeker(server)
-	seeker.check()
+	seeker.check(context.Background())
 
 	upGauge := testutil.ToFloat64(seeker.up)
 	if upGauge != 0 {
@@ -96,7 +99,7 @@ func TestSeekerImplCheckUptimeWith500ExpectDown(t *testing.T) {
 	defer server.Close()
 
 	seeker := setupSeeker(server)
-	seeker.check()
+	seeker.check(context.Background())
 
 	upGauge := testutil.ToFloat64(seeker.up)
 	if upGauge != 0 {
@@ -117,7 +120,7 @@ func TestSeekerImplCheckUptimeWithHighLatencyExpectInHistogram(t *testing.T) {
 	defer server.Close()
 
 	seeker := setupSeeker(server)
-	seeker.check()
+	seeker.check(context.Background())
 
 	latencyGauge := testutil.ToFloat64(seeker.latency)
 	if latencyGauge == 0 {
@@ -136,3 +139,563 @@ func TestSeekerImplCheckUptimeWithHighLatencyExpectInHistogram(t *testing.T) {
 		t.Errorf("Expected latency to be at least 500ms, got %vms", observedLatency)
 	}
 }
+
+func TestSeekerImplCheckUptimeWithFailedAssertionExpectDown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status": "maintenance"}`))
+	}))
+	defer server.Close()
+
+	seeker, err := NewSeeker(
+		Host{
+			Host:     server.URL,
+			Interval: 60,
+			Timeout:  1,
+			Assertions: AssertionOptions{
+				JSON: []string{`$.status == "ok"`},
+			},
+		},
+		prometheus.NewRegistry(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create seeker: %v", err)
+	}
+	seeker.check(context.Background())
+
+	upGauge := testutil.ToFloat64(seeker.up)
+	if upGauge != 0 {
+		t.Errorf("Expected upGauge to be 0, got %v", upGauge)
+	}
+
+	reasonGauge := testutil.ToFloat64(seeker.downReason.WithLabelValues(reasonAssertionFailed))
+	if reasonGauge != 1 {
+		t.Errorf("Expected reasonGauge to be 1, got %v", reasonGauge)
+	}
+}
+
+func TestSeekerImplCheckUptimeWithSatisfiedAssertionExpectUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+
+	seeker, err := NewSeeker(
+		Host{
+			Host:     server.URL,
+			Interval: 60,
+			Timeout:  1,
+			Assertions: AssertionOptions{
+				Contains: []string{"status"},
+				JSON:     []string{`$.status == "ok"`},
+			},
+		},
+		prometheus.NewRegistry(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create seeker: %v", err)
+	}
+	seeker.check(context.Background())
+
+	upGauge := testutil.ToFloat64(seeker.up)
+	if upGauge != 1 {
+		t.Errorf("Expected upGauge to be 1, got %v", upGauge)
+	}
+}
+
+// histogramFor returns the histogram of the seeker for the given outcome.
+func histogramFor(t *testing.T, seeker *SeekerImpl, outcome string) *dto.Histogram {
+	metric := &dto.Metric{}
+	err := seeker.histogram.WithLabelValues(outcome).(prometheus.Metric).Write(metric)
+	if err != nil {
+		t.Fatalf("Failed to write histogram metric: %v", err)
+	}
+
+	return metric.GetHistogram()
+}
+
+func TestSeekerImplCheckUptimeExpectLatencyHistogramByOutcome(t *testing.T) {
+	var fail bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	seeker := setupSeeker(server)
+	seeker.check(context.Background())
+	fail = true
+	seeker.check(context.Background())
+	seeker.check(context.Background())
+
+	success := histogramFor(t, seeker, "success")
+	if success.GetSampleCount() != 1 {
+		t.Errorf("Expected 1 successful observation, got %v", success.GetSampleCount())
+	}
+	if success.GetSampleSum() < 0.01 {
+		t.Errorf("Expected latency to be at least 0.01s, got %vs", success.GetSampleSum())
+	}
+
+	failure := histogramFor(t, seeker, "failure")
+	if failure.GetSampleCount() != 2 {
+		t.Errorf("Expected 2 failed observations, got %v", failure.GetSampleCount())
+	}
+}
+
+func TestSeekerWithCustomBucketsAndNativeHistogram(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	seeker, err := NewSeeker(
+		Host{
+			Host:     server.URL,
+			Interval: 60,
+			Timeout:  1,
+			Histogram: HistogramOptions{
+				Buckets: []float64{0.1, 0.5, 1},
+				Native:  true,
+			},
+		},
+		prometheus.NewRegistry(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create seeker: %v", err)
+	}
+	seeker.check(context.Background())
+
+	histogram := histogramFor(t, seeker, "success")
+	if len(histogram.GetBucket()) != 3 {
+		t.Errorf("Expected 3 buckets, got %v", len(histogram.GetBucket()))
+	}
+	if histogram.GetSchema() == 0 && histogram.GetZeroThreshold() == 0 {
+		t.Errorf("Expected native histogram to be enabled")
+	}
+}
+
+func TestNewSeekerWithUnorderedBucketsExpectError(t *testing.T) {
+	_, err := NewSeeker(
+		Host{
+			Host:      "http://example.com",
+			Interval:  60,
+			Timeout:   1,
+			Histogram: HistogramOptions{Buckets: []float64{1, 0.5}},
+		},
+		prometheus.NewRegistry(),
+	)
+	if err == nil {
+		t.Errorf("Expected an error for unordered buckets")
+	}
+}
+
+func TestSeekerImplCheckUptimeExpectChecksCountedByResultAndReason(t *testing.T) {
+	var status = http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	seeker := setupSeeker(server)
+	seeker.check(context.Background())
+	status = http.StatusBadGateway
+	seeker.check(context.Background())
+	server.Close()
+	seeker.check(context.Background())
+
+	if count := testutil.ToFloat64(seeker.checks.WithLabelValues("success", "")); count != 1 {
+		t.Errorf("Expected 1 successful check, got %v", count)
+	}
+	if count := testutil.ToFloat64(seeker.checks.WithLabelValues("failure", reasonBadStatus)); count != 1 {
+		t.Errorf("Expected 1 check failed with bad_status, got %v", count)
+	}
+	if count := testutil.ToFloat64(seeker.checks.WithLabelValues("failure", reasonConnectionRefused)); count != 1 {
+		t.Errorf("Expected 1 check failed with connection_refused, got %v", count)
+	}
+	if reason := testutil.ToFloat64(seeker.downReason.WithLabelValues(reasonConnectionRefused)); reason != 1 {
+		t.Errorf("Expected the down reason to be connection_refused, got %v", reason)
+	}
+}
+
+func TestSeekerImplCheckWithTransitionsExpectEvents(t *testing.T) {
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	dispatcher := setupDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.Register("test", notifier, 1, 0)
+
+	seeker, err := NewSeeker(Host{Host: server.URL, Interval: 60, Timeout: 1}, prometheus.NewRegistry(), WithDispatcher(dispatcher, nil))
+	assert.NoError(t, err)
+
+	seeker.check(context.Background())
+	seeker.check(context.Background())
+	status = http.StatusOK
+	seeker.check(context.Background())
+	dispatcher.Wait()
+
+	if assert.Len(t, notifier.events, 2) {
+		assert.Equal(t, stateDown, notifier.events[0].NewState)
+		assert.Equal(t, reasonBadStatus, notifier.events[0].Reason)
+		assert.Equal(t, stateUp, notifier.events[1].NewState)
+		assert.Equal(t, stateDown, notifier.events[1].OldState)
+		assert.Greater(t, notifier.events[1].Duration, time.Duration(0))
+	}
+}
+
+// setupSequenceServer starts a server answering with the given status codes in order, then with the last one.
+func setupSequenceServer(t *testing.T, statuses ...int) *httptest.Server {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[min(calls, len(statuses)-1)])
+		calls++
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestSeekerImplCheckWithRetryExpectUp(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusBadGateway, http.StatusOK)
+
+	seeker, err := NewSeeker(Host{Host: server.URL, Retries: 1, Interval: 60, Timeout: 1}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+	seeker.retryDelay = time.Millisecond
+
+	seeker.check(context.Background())
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.up))
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.lastCheckUp))
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.checks.WithLabelValues("success", "")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.checks.WithLabelValues("failure", reasonBadStatus)))
+}
+
+func TestSeekerImplCheckWithFailThresholdExpectDownAfterConsecutiveFailures(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusOK, http.StatusBadGateway)
+
+	seeker, err := NewSeeker(Host{Host: server.URL, FailThreshold: 3, Interval: 60, Timeout: 1}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	seeker.check(context.Background())
+	seeker.check(context.Background())
+	seeker.check(context.Background())
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.up))
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.lastCheckUp))
+	assert.Equal(t, 0, testutil.CollectAndCount(seeker.downReason))
+
+	seeker.check(context.Background())
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.up))
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.downReason.WithLabelValues(reasonBadStatus)))
+}
+
+func TestSeekerImplCheckWithFailureAtStartupExpectUpUnderFailThreshold(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusBadGateway)
+
+	seeker, err := NewSeeker(Host{Host: server.URL, FailThreshold: 2, Interval: 60, Timeout: 1}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	seeker.check(context.Background())
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.up))
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.lastCheckUp))
+	assert.Equal(t, 0, testutil.CollectAndCount(seeker.downReason))
+}
+
+func TestSeekerImplCheckWithRecoverThresholdExpectUpAfterConsecutiveSuccesses(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusBadGateway, http.StatusOK)
+
+	dispatcher := setupDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.Register("test", notifier, 1, 0)
+
+	seeker, err := NewSeeker(Host{Host: server.URL, RecoverThreshold: 2, Interval: 60, Timeout: 1}, prometheus.NewRegistry(), WithDispatcher(dispatcher, nil))
+	assert.NoError(t, err)
+
+	seeker.check(context.Background())
+	seeker.check(context.Background())
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.up))
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.lastCheckUp))
+
+	seeker.check(context.Background())
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.up))
+	assert.Equal(t, 0, testutil.CollectAndCount(seeker.downReason))
+
+	dispatcher.Wait()
+	assert.Len(t, notifier.events, 2)
+}
+
+func TestSeekerImplCheckWithFlappingHostExpectTransitionsSuppressed(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusBadGateway, http.StatusOK, http.StatusBadGateway, http.StatusOK, http.StatusBadGateway, http.StatusOK)
+
+	dispatcher := setupDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.Register("test", notifier, 1, 0)
+
+	seeker, err := NewSeeker(Host{
+		Host:     server.URL,
+		Interval: 60,
+		Timeout:  1,
+		Flapping: FlappingOptions{Threshold: 3, Window: 600},
+	}, prometheus.NewRegistry(), WithDispatcher(dispatcher, nil))
+	assert.NoError(t, err)
+
+	for range 6 {
+		seeker.check(context.Background())
+	}
+	dispatcher.Wait()
+
+	// the host is flapping once it changes state more than 3 times
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.flappingGauge))
+	if assert.Len(t, notifier.events, 4) {
+		assert.Equal(t, eventTransition, notifier.events[0].Kind)
+		assert.Equal(t, eventTransition, notifier.events[1].Kind)
+		assert.Equal(t, eventTransition, notifier.events[2].Kind)
+		assert.Equal(t, eventFlappingStarted, notifier.events[3].Kind)
+		assert.Equal(t, stateUp, notifier.events[3].NewState)
+	}
+}
+
+func TestSeekerImplCheckWithStableFlappingHostExpectFlappingStopped(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusOK)
+
+	dispatcher := setupDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.Register("test", notifier, 1, 0)
+
+	seeker, err := NewSeeker(Host{
+		Host:     server.URL,
+		Interval: 60,
+		Timeout:  1,
+		Flapping: FlappingOptions{Threshold: 3, Window: 600},
+	}, prometheus.NewRegistry(), WithDispatcher(dispatcher, nil))
+	assert.NoError(t, err)
+
+	seeker.flapping = true
+	seeker.transitions = []time.Time{time.Now().Add(-5 * time.Minute)}
+	seeker.check(context.Background())
+	assert.True(t, seeker.flapping)
+
+	seeker.transitions = []time.Time{time.Now().Add(-11 * time.Minute)}
+	seeker.check(context.Background())
+	dispatcher.Wait()
+
+	assert.False(t, seeker.flapping)
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.flappingGauge))
+	if assert.Len(t, notifier.events, 1) {
+		assert.Equal(t, eventFlappingStopped, notifier.events[0].Kind)
+		assert.Equal(t, stateUp, notifier.events[0].NewState)
+	}
+}
+
+func TestNewSeekerWithFlappingWithoutWindowExpectError(t *testing.T) {
+	_, err := NewSeeker(Host{Host: "http://example.com", Flapping: FlappingOptions{Threshold: 3}, Interval: 60, Timeout: 1}, prometheus.NewRegistry())
+	assert.Error(t, err)
+}
+
+func TestSeekerImplCheckInMaintenanceExpectNotificationsSuppressed(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusBadGateway)
+
+	dispatcher := setupDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.Register("test", notifier, 1, 0)
+
+	maintenance, err := NewMaintenance(nil)
+	assert.NoError(t, err)
+	silence, err := maintenance.AddSilence(Silence{Hosts: []string{"api"}, EndsAt: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+
+	seeker, err := NewSeeker(Host{Name: "api", Host: server.URL, Interval: 60, Timeout: 1}, prometheus.NewRegistry(),
+		WithDispatcher(dispatcher, nil), WithMaintenance(maintenance))
+	assert.NoError(t, err)
+
+	seeker.check(context.Background())
+	dispatcher.Wait()
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.maintenanceGauge))
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.up))
+	assert.Empty(t, notifier.events)
+
+	// the host is still down when the maintenance ends, which is notified
+	maintenance.RemoveSilence(silence.ID)
+	seeker.check(context.Background())
+	dispatcher.Wait()
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.maintenanceGauge))
+	if assert.Len(t, notifier.events, 1) {
+		assert.Equal(t, stateDown, notifier.events[0].NewState)
+		assert.Equal(t, reasonBadStatus, notifier.events[0].Reason)
+	}
+}
+
+func TestSeekerImplCheckWithDependencyDownExpectFailureSuppressed(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusBadGateway)
+
+	dispatcher := setupDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.Register("test", notifier, 1, 0)
+
+	states := NewStateStore()
+	states.Set("gateway", HostState{Up: false})
+
+	seeker, err := NewSeeker(Host{Name: "orders", Host: server.URL, Interval: 60, Timeout: 1}, prometheus.NewRegistry(),
+		WithDispatcher(dispatcher, nil), WithStates(states, []string{"gateway"}))
+	assert.NoError(t, err)
+
+	seeker.check(context.Background())
+	dispatcher.Wait()
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.up))
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.downReason.WithLabelValues(reasonDependency)))
+	assert.Empty(t, notifier.events)
+
+	state, ok := states.Get("orders")
+	assert.True(t, ok)
+	assert.False(t, state.Up)
+
+	// the gateway recovers but the host is still down, which is notified with the actual reason
+	states.Set("gateway", HostState{Up: true})
+	seeker.check(context.Background())
+	dispatcher.Wait()
+	if assert.Len(t, notifier.events, 1) {
+		assert.Equal(t, stateDown, notifier.events[0].NewState)
+		assert.Equal(t, reasonBadStatus, notifier.events[0].Reason)
+	}
+}
+
+func TestSeekerImplCheckWithOngoingOutageExpectEscalationAndReminders(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK)
+
+	dispatcher := setupDispatcher(t)
+	ops := &recordingNotifier{}
+	oncall := &recordingNotifier{}
+	dispatcher.Register("ops", ops, 1, 0)
+	dispatcher.Register("oncall", oncall, 1, 0)
+
+	seeker, err := NewSeeker(Host{
+		Host:     server.URL,
+		Interval: 60,
+		Timeout:  1,
+		Escalation: EscalationOptions{
+			ReminderInterval: 1800,
+			EscalateAfter:    3600,
+			EscalateTo:       []string{"oncall"},
+		},
+	}, prometheus.NewRegistry(), WithDispatcher(dispatcher, []string{"ops"}))
+	assert.NoError(t, err)
+
+	seeker.check(context.Background())
+
+	// the outage lasts long enough to be escalated
+	seeker.downSince = seeker.downSince.Add(-2 * time.Hour)
+	seeker.check(context.Background())
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.escalatedGauge))
+
+	// the next reminder is due, and is sent to the escalation notifiers too
+	seeker.lastReminder = seeker.lastReminder.Add(-time.Hour)
+	seeker.check(context.Background())
+
+	seeker.check(context.Background())
+	dispatcher.Wait()
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.escalatedGauge))
+
+	var opsKinds, oncallKinds []string
+	for _, event := range ops.events {
+		opsKinds = append(opsKinds, event.Kind+":"+event.NewState)
+	}
+	for _, event := range oncall.events {
+		oncallKinds = append(oncallKinds, event.Kind+":"+event.NewState)
+	}
+	assert.Equal(t, []string{"transition:down", "reminder:down", "transition:up"}, opsKinds)
+	assert.Equal(t, []string{"escalation:down", "reminder:down", "transition:up"}, oncallKinds)
+}
+
+func TestSeekerImplCheckWithoutNotifiersExpectEscalationNotifiersOnlyOnceEscalated(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK)
+
+	dispatcher := setupDispatcher(t)
+	ops := &recordingNotifier{}
+	oncall := &recordingNotifier{}
+	dispatcher.Register("ops", ops, 1, 0)
+	dispatcher.Register("oncall", oncall, 1, 0)
+
+	seeker, err := NewSeeker(Host{
+		Host:     server.URL,
+		Interval: 60,
+		Timeout:  1,
+		Escalation: EscalationOptions{
+			EscalateAfter: 3600,
+			EscalateTo:    []string{"oncall"},
+		},
+	}, prometheus.NewRegistry(), WithDispatcher(dispatcher, nil))
+	assert.NoError(t, err)
+
+	seeker.check(context.Background())
+	dispatcher.Wait()
+	assert.Len(t, ops.events, 1)
+	assert.Empty(t, oncall.events)
+
+	seeker.downSince = seeker.downSince.Add(-2 * time.Hour)
+	seeker.check(context.Background())
+	seeker.check(context.Background())
+	dispatcher.Wait()
+
+	var opsKinds, oncallKinds []string
+	for _, event := range ops.events {
+		opsKinds = append(opsKinds, event.Kind+":"+event.NewState)
+	}
+	for _, event := range oncall.events {
+		oncallKinds = append(oncallKinds, event.Kind+":"+event.NewState)
+	}
+	assert.Equal(t, []string{"transition:down", "transition:up"}, opsKinds)
+	assert.Equal(t, []string{"escalation:down", "transition:up"}, oncallKinds)
+}
+
+func TestSeekerImplCheckWithCancelledRetryExpectNotCounted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	seeker, err := NewSeeker(Host{Host: server.URL, Retries: 3, RetryDelay: 60000, Interval: 60, Timeout: 1}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, seeker.retryDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result := seeker.check(ctx)
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.ErrorIs(t, result.Err, context.DeadlineExceeded)
+	assert.Zero(t, seeker.consecutiveFailures)
+	assert.True(t, seeker.previouslyUp)
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.checks.WithLabelValues("failure", reasonBadStatus)))
+}
+
+func TestNewSeekerWithoutRetryDelayExpectDefault(t *testing.T) {
+	seeker, err := NewSeeker(Host{Host: "http://example.com", Interval: 60, Timeout: 1}, prometheus.NewRegistry())
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCheckRetryDelay, seeker.retryDelay)
+}
+
+func TestNewSeekerWithPreviousExpectGaugesCarriedOver(t *testing.T) {
+	server := setupSequenceServer(t, http.StatusOK)
+	host := Host{Host: server.URL, Interval: 60, Timeout: 1}
+
+	unchecked, err := NewSeeker(host, prometheus.NewRegistry())
+	assert.NoError(t, err)
+	seeker, err := NewSeeker(host, prometheus.NewRegistry(), WithPrevious(unchecked))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, testutil.ToFloat64(seeker.up))
+
+	unchecked.check(context.Background())
+	seeker, err = NewSeeker(host, prometheus.NewRegistry(), WithPrevious(unchecked))
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(seeker.up))
+	assert.Zero(t, testutil.CollectAndCount(seeker.downReason))
+}